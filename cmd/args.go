@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -20,16 +21,37 @@ var (
 			if err != nil {
 				return err
 			}
+			config, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+			scafall.WithConfig(config)(&s)
 			subPathVal, err := cmd.Flags().GetString(subPath)
 			if err == nil {
 				scafall.WithSubPath(subPathVal)(&s)
 			}
+			refVal, err := cmd.Flags().GetString(refFlag)
+			if err == nil && refVal != "" {
+				scafall.WithRef(refVal)(&s)
+			}
+			checksumVal, err := cmd.Flags().GetString(checksumFlag)
+			if err == nil && checksumVal != "" {
+				scafall.WithChecksum(checksumVal)(&s)
+			}
+			cacheOptions(cmd, &s)
+			authOptions(cmd, &s)
 
-			description, sArgs, _ := s.TemplateArguments()
-			fmt.Println(description)
-			for _, a := range sArgs {
-				fmt.Printf("\t%s\n", a)
+			description, err := s.Describe()
+			if err != nil {
+				return err
+			}
+			jsonVal, _ := cmd.Flags().GetBool(jsonFlag)
+			if jsonVal {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(description)
 			}
+			fmt.Fprint(cmd.OutOrStdout(), description.String())
 			return nil
 		},
 	}
@@ -37,4 +59,10 @@ var (
 
 func init() {
 	argsCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	argsCmd.Flags().StringP(refFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	argsCmd.Flags().Duration(cacheMaxAgeFlag, scafall.DefaultCacheMaxAge, "fetch cached branches and tags again once older than this")
+	argsCmd.Flags().Bool(offlineFlag, false, "only use templates that are already cached")
+	argsCmd.Flags().String(checksumFlag, "", "expected sha256 checksum of an archive template")
+	argsCmd.Flags().Bool(jsonFlag, false, "describe the prompts and variables of the template as JSON")
+	addAuthFlags(argsCmd)
 }
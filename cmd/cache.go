@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks-community/scafall/pkg"
+)
+
+const (
+	olderThanFlag = "older-than"
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "manage cached templates",
+		Long:  `Templates fetched from git repositories are cached, list and remove cached templates.`,
+	}
+
+	cacheListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list cached templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir(cmd)
+			if err != nil {
+				return err
+			}
+			entries, err := scafall.ListCache(dir)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				refs := ""
+				if len(e.Refs) > 0 {
+					refs = fmt.Sprintf(" (%s)", strings.Join(e.Refs, ", "))
+				}
+				fmt.Printf("%s %s%s last used %s\n", e.URL, e.Commit, refs, e.LastUsed.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "remove templates that have not been used recently",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir(cmd)
+			if err != nil {
+				return err
+			}
+			olderThan, err := cmd.Flags().GetDuration(olderThanFlag)
+			if err != nil {
+				return err
+			}
+			removed, err := scafall.PruneCache(dir, olderThan)
+			for _, e := range removed {
+				fmt.Printf("removed %s %s\n", e.URL, e.Commit)
+			}
+			return err
+		},
+	}
+
+	cacheClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "remove all cached templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir(cmd)
+			if err != nil {
+				return err
+			}
+			return scafall.ClearCache(dir)
+		},
+	}
+)
+
+// The cache directory given by the flags of cmd, or by the user config
+func cacheDir(cmd *cobra.Command) (string, error) {
+	dir, _ := cmd.Flags().GetString(cacheDirFlag)
+	if cmd.Flags().Changed(cacheDirFlag) {
+		return dir, nil
+	}
+	config, err := readConfig(cmd)
+	if err != nil {
+		return "", err
+	}
+	if config.CacheDir != nil {
+		return *config.CacheDir, nil
+	}
+	return dir, nil
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cachePruneCmd.Flags().Duration(olderThanFlag, 30*24*time.Hour, "remove templates not used within this duration")
+}
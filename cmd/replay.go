@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks-community/scafall/pkg"
+)
+
+var (
+	replayCmd = &cobra.Command{
+		Use:   "replay project",
+		Short: "generate a project again from its recorded template and answers",
+		Long:  `Generate a project again from the template revision and answers recorded in it, without prompting.  The project is generated in place unless another path is given, and arguments override the recorded answers.  Files edited since the project was generated are backed up unless --on-conflict says otherwise.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+			opts := []scafall.Option{scafall.WithConfig(config)}
+			outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
+			if err == nil && outputDirVal != "" {
+				opts = append(opts, scafall.WithOutputFolder(outputDirVal))
+			}
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err == nil {
+				opts = append(opts, scafall.WithArguments(argumentsVal))
+			}
+			onConflictVal, err := cmd.Flags().GetString(onConflictFlag)
+			if err == nil && cmd.Flags().Changed(onConflictFlag) {
+				policy, err := scafall.ParseConflictPolicy(onConflictVal)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, scafall.WithConflictPolicy(policy))
+			}
+
+			s, err := scafall.NewScafallFromRecord(args[0], opts...)
+			if err != nil {
+				return err
+			}
+			cacheOptions(cmd, &s)
+			authOptions(cmd, &s)
+			return s.Scaffold()
+		},
+	}
+)
+
+func init() {
+	replayCmd.Flags().StringP(outputFolderFlag, "p", "", "generate the project in the provided output directory rather than in place")
+	replayCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
+	replayCmd.Flags().String(onConflictFlag, string(scafall.ConflictBackup), "handle existing files that differ from generated files: fail, skip, overwrite or backup")
+	replayCmd.Flags().Duration(cacheMaxAgeFlag, scafall.DefaultCacheMaxAge, "fetch cached branches and tags again once older than this")
+	replayCmd.Flags().Bool(offlineFlag, false, "only use templates that are already cached")
+	addAuthFlags(replayCmd)
+}
@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	scafall "github.com/buildpacks-community/scafall/pkg"
 )
@@ -10,13 +16,33 @@ const (
 	outputFolderFlag = "path"
 	argumentsFlag    = "arg"
 	subPath          = "sub-path"
+	refFlag          = "ref"
+	cacheDirFlag     = "cache-dir"
+	cacheMaxAgeFlag  = "cache-max-age"
+	offlineFlag      = "offline"
+	sshKeyFlag       = "ssh-key"
+	netrcFlag        = "netrc"
+	credHelperFlag   = "credential-helper"
+	checksumFlag     = "checksum"
+	onConflictFlag   = "on-conflict"
+	dryRunFlag       = "dry-run"
+	diffFlag         = "diff"
+	jsonFlag         = "json"
+	strictFlag       = "strict"
+	answersFlag      = "answers"
+	nonInteractive   = "non-interactive"
+	noRecordFlag     = "no-record"
+	configFlag       = "config"
+
+	sshPassphraseEnv = "SCAFALL_SSH_KEY_PASSPHRASE"
+	gitCredHelper    = "git"
 )
 
 var (
 	rootCmd = &cobra.Command{
-		Use:   "scafall gitRepository",
+		Use:   "scafall template",
 		Short: "A project generation tool",
-		Long:  `Scafall creates new project from project templates.`,
+		Long:  `Scafall creates new project from project templates.  A template is a git repository, a local directory or a tar.gz, tgz, tar or zip archive.`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			url := args[0]
@@ -25,29 +51,176 @@ var (
 			if err != nil {
 				return err
 			}
+			config, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+			scafall.WithConfig(config)(&s)
+			if config.OutputPath != "" {
+				scafall.WithOutputFolder(config.OutputPath)(&s)
+			}
 			outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
-			if err == nil {
+			if err == nil && (cmd.Flags().Changed(outputFolderFlag) || config.OutputPath == "") {
 				scafall.WithOutputFolder(outputDirVal)(&s)
 			}
 			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
 			if err == nil {
 				scafall.WithArguments(argumentsVal)(&s)
 			}
+			answersVal, err := cmd.Flags().GetString(answersFlag)
+			if err == nil && answersVal != "" {
+				scafall.WithAnswersFile(answersVal)(&s)
+			}
 			subPathVal, err := cmd.Flags().GetString(subPath)
 			if err == nil {
 				scafall.WithSubPath(subPathVal)(&s)
 			}
+			refVal, err := cmd.Flags().GetString(refFlag)
+			if err == nil && refVal != "" {
+				scafall.WithRef(refVal)(&s)
+			}
+			checksumVal, err := cmd.Flags().GetString(checksumFlag)
+			if err == nil && checksumVal != "" {
+				scafall.WithChecksum(checksumVal)(&s)
+			}
+			onConflictVal, err := cmd.Flags().GetString(onConflictFlag)
+			if err == nil && cmd.Flags().Changed(onConflictFlag) {
+				policy, err := scafall.ParseConflictPolicy(onConflictVal)
+				if err != nil {
+					return err
+				}
+				scafall.WithConflictPolicy(policy)(&s)
+			}
+			strictVal, err := cmd.Flags().GetBool(strictFlag)
+			if err == nil && strictVal {
+				scafall.WithStrict()(&s)
+			}
+			nonInteractiveVal, err := cmd.Flags().GetBool(nonInteractive)
+			if err == nil && (nonInteractiveVal || !stdinIsTerminal()) {
+				scafall.WithNonInteractive()(&s)
+			}
+			noRecordVal, err := cmd.Flags().GetBool(noRecordFlag)
+			if err == nil && noRecordVal {
+				scafall.WithoutRecord()(&s)
+			}
+			cacheOptions(cmd, &s)
+			authOptions(cmd, &s)
+
+			dryRunVal, _ := cmd.Flags().GetBool(dryRunFlag)
+			diffVal, _ := cmd.Flags().GetBool(diffFlag)
+			jsonVal, _ := cmd.Flags().GetBool(jsonFlag)
+			if dryRunVal || diffVal || jsonVal {
+				return printPlan(cmd.OutOrStdout(), &s, diffVal, jsonVal)
+			}
 
 			return s.Scaffold()
 		},
 	}
 )
 
+// Whether the user can be asked questions on stdin, which is not the case in a
+// pipeline
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Print the plan of s, as JSON or as a summary optionally followed by diffs of
+// the existing files that change
+func printPlan(out io.Writer, s *scafall.Scafall, diff bool, asJSON bool) error {
+	plan, err := s.Plan()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+	fmt.Fprint(out, plan.String())
+	if diff {
+		for _, f := range plan.Files {
+			fmt.Fprint(out, f.Diff)
+		}
+	}
+	return nil
+}
+
+// Read the user config file given by the flags of cmd
+func readConfig(cmd *cobra.Command) (scafall.Config, error) {
+	configVal, err := cmd.Flags().GetString(configFlag)
+	if err != nil {
+		return scafall.Config{}, nil
+	}
+	return scafall.ReadConfig(configVal)
+}
+
+// Apply the cache flags of cmd that are set to s, in place of the config
+func cacheOptions(cmd *cobra.Command, s *scafall.Scafall) {
+	cacheDirVal, err := cmd.Flags().GetString(cacheDirFlag)
+	if err == nil && cmd.Flags().Changed(cacheDirFlag) {
+		scafall.WithCacheDir(cacheDirVal)(s)
+	}
+	cacheMaxAgeVal, err := cmd.Flags().GetDuration(cacheMaxAgeFlag)
+	if err == nil && cmd.Flags().Changed(cacheMaxAgeFlag) {
+		scafall.WithCacheMaxAge(cacheMaxAgeVal)(s)
+	}
+	offlineVal, err := cmd.Flags().GetBool(offlineFlag)
+	if err == nil && offlineVal {
+		scafall.WithOffline()(s)
+	}
+}
+
+// Apply the credential flags of cmd to s, ahead of the default credentials
+func authOptions(cmd *cobra.Command, s *scafall.Scafall) {
+	auths := []scafall.Auth{}
+	sshKeyVal, err := cmd.Flags().GetString(sshKeyFlag)
+	if err == nil && sshKeyVal != "" {
+		auths = append(auths, scafall.SSHKeyAuth(sshKeyVal, os.Getenv(sshPassphraseEnv)))
+	}
+	netrcVal, err := cmd.Flags().GetString(netrcFlag)
+	if err == nil && netrcVal != "" {
+		auths = append(auths, scafall.NetrcAuth(netrcVal))
+	}
+	credHelperVal, err := cmd.Flags().GetString(credHelperFlag)
+	if err == nil && credHelperVal != "" {
+		if credHelperVal == gitCredHelper {
+			credHelperVal = ""
+		}
+		auths = append(auths, scafall.CredentialHelperAuth(credHelperVal))
+	}
+	auths = append(auths, scafall.DefaultAuth())
+	scafall.WithAuth(scafall.ChainAuth(auths...))(s)
+}
+
+func addAuthFlags(cmd *cobra.Command) {
+	cmd.Flags().String(sshKeyFlag, "", "private key file for ssh template repositories, passphrase read from "+sshPassphraseEnv)
+	cmd.Flags().String(netrcFlag, "", "netrc file with credentials for https template repositories")
+	cmd.Flags().String(credHelperFlag, "", "git credential helper for https template repositories, \"git\" uses the helpers configured for git")
+}
+
 func init() {
 	rootCmd.AddCommand(argsCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.PersistentFlags().String(cacheDirFlag, scafall.DefaultCacheDir(), "directory in which templates are cached, empty to disable caching")
+	rootCmd.PersistentFlags().String(configFlag, scafall.DefaultConfigFile(), "user config file with default answers and settings")
 	rootCmd.Flags().StringP(outputFolderFlag, "p", ".", "scaffold project in the provided output directory")
 	rootCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
+	rootCmd.Flags().String(answersFlag, "", "read arguments from a TOML, YAML or JSON file, or stdin when -")
 	rootCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	rootCmd.Flags().StringP(refFlag, "r", "", "use a git branch, tag or commit of the template repository")
+	rootCmd.Flags().Duration(cacheMaxAgeFlag, scafall.DefaultCacheMaxAge, "fetch cached branches and tags again once older than this")
+	rootCmd.Flags().Bool(offlineFlag, false, "only use templates that are already cached")
+	rootCmd.Flags().String(checksumFlag, "", "expected sha256 checksum of an archive template")
+	rootCmd.Flags().String(onConflictFlag, string(scafall.ConflictFail), "handle existing files that differ from generated files: fail, skip, overwrite, prompt or backup")
+	rootCmd.Flags().Bool(dryRunFlag, false, "show the files that would be created, overwritten or skipped without writing them")
+	rootCmd.Flags().Bool(diffFlag, false, "show a dry run with diffs of existing files that would change")
+	rootCmd.Flags().Bool(jsonFlag, false, "show a dry run as JSON")
+	rootCmd.Flags().Bool(strictFlag, false, "fail, rather than warn, when an argument answers no prompt of the template")
+	rootCmd.Flags().Bool(nonInteractive, false, "never prompt, taking the defaults of prompts; set when stdin is not a terminal")
+	rootCmd.Flags().Bool(noRecordFlag, false, "do not record the template and answers in .scafall/answers.toml")
+	addAuthFlags(rootCmd)
 }
 
 // Execute executes the root command.
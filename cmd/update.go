@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	scafall "github.com/buildpacks-community/scafall/pkg"
+)
+
+var (
+	updateCmd = &cobra.Command{
+		Use:   "update [template]",
+		Short: "apply a newer revision of the template to a generated project",
+		Long:  `Merge the changes made to the template, since the revision a project was generated from, into the project.  The template and answers recorded in the project are used unless template or arguments are given.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			url := ""
+			if len(args) == 1 {
+				url = args[0]
+			}
+			s, err := scafall.NewScafall(url)
+			if err != nil {
+				return err
+			}
+			config, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+			scafall.WithConfig(config)(&s)
+			outputDirVal, err := cmd.Flags().GetString(outputFolderFlag)
+			if err == nil {
+				scafall.WithOutputFolder(outputDirVal)(&s)
+			}
+			argumentsVal, err := cmd.Flags().GetStringToString(argumentsFlag)
+			if err == nil {
+				scafall.WithArguments(argumentsVal)(&s)
+			}
+			answersVal, err := cmd.Flags().GetString(answersFlag)
+			if err == nil && answersVal != "" {
+				scafall.WithAnswersFile(answersVal)(&s)
+			}
+			subPathVal, err := cmd.Flags().GetString(subPath)
+			if err == nil {
+				scafall.WithSubPath(subPathVal)(&s)
+			}
+			refVal, err := cmd.Flags().GetString(refFlag)
+			if err == nil && refVal != "" {
+				scafall.WithRef(refVal)(&s)
+			}
+			nonInteractiveVal, err := cmd.Flags().GetBool(nonInteractive)
+			if err == nil && (nonInteractiveVal || !stdinIsTerminal()) {
+				scafall.WithNonInteractive()(&s)
+			}
+			cacheOptions(cmd, &s)
+			authOptions(cmd, &s)
+
+			result, err := s.Update()
+			for _, path := range result.Created {
+				fmt.Printf("created %s\n", path)
+			}
+			for _, path := range result.Updated {
+				fmt.Printf("updated %s\n", path)
+			}
+			for _, path := range result.Removed {
+				fmt.Printf("removed %s\n", path)
+			}
+			for _, path := range result.Conflicts {
+				fmt.Printf("conflict %s\n", path)
+			}
+			if err != nil {
+				return err
+			}
+			if len(result.Conflicts) > 0 {
+				return fmt.Errorf("%d files have conflicts with the template", len(result.Conflicts))
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	updateCmd.Flags().StringP(outputFolderFlag, "p", ".", "update the project in the provided directory")
+	updateCmd.Flags().StringToStringP(argumentsFlag, "o", map[string]string{}, "provide overrides as key-value pairs")
+	updateCmd.Flags().String(answersFlag, "", "read overrides from a TOML, YAML or JSON file, or stdin when -")
+	updateCmd.Flags().StringP(subPath, "s", "", "use sub directory in template project to scaffold project")
+	updateCmd.Flags().StringP(refFlag, "r", "", "update to a git branch, tag or commit of the template repository")
+	updateCmd.Flags().Duration(cacheMaxAgeFlag, scafall.DefaultCacheMaxAge, "fetch cached branches and tags again once older than this")
+	updateCmd.Flags().Bool(offlineFlag, false, "only use templates that are already cached")
+	updateCmd.Flags().Bool(nonInteractive, false, "never prompt, taking the defaults of prompts; set when stdin is not a terminal")
+	addAuthFlags(updateCmd)
+}
@@ -0,0 +1,61 @@
+package scafall
+
+import (
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// Auth resolves the credentials used to access a git repository.  Implement
+// Auth to plug in a custom source of credentials, returning a nil AuthMethod
+// when there are no credentials for a URL.
+type Auth = internal.Auth
+
+// AuthFunc adapts a function to an Auth.
+type AuthFunc = internal.AuthFunc
+
+// DefaultAuth reads credentials from the SCAFALL_GIT_USERNAME,
+// SCAFALL_GIT_PASSWORD and SCAFALL_GIT_TOKEN environment variables for the
+// SCAFALL_GIT_HOST, the netrc file and a running ssh agent.
+func DefaultAuth() Auth {
+	return internal.DefaultAuth()
+}
+
+// ChainAuth uses the credentials of the first Auth that has any.
+func ChainAuth(auths ...Auth) Auth {
+	return internal.ChainAuth(auths...)
+}
+
+// BasicAuth uses a username and password, or token, for http(s) repositories.
+func BasicAuth(username string, password string) Auth {
+	return internal.BasicAuth(username, password)
+}
+
+// EnvAuth uses SCAFALL_GIT_USERNAME and SCAFALL_GIT_PASSWORD, or
+// SCAFALL_GIT_TOKEN, for https repositories on the host named by
+// SCAFALL_GIT_HOST.  Without a host the credentials are not used.
+func EnvAuth() Auth {
+	return internal.EnvAuth()
+}
+
+// NetrcAuth uses the credentials for the repository host in a netrc file.  An
+// empty netrcFile uses $NETRC or ~/.netrc.
+func NetrcAuth(netrcFile string) Auth {
+	return internal.NetrcAuth(netrcFile)
+}
+
+// SSHAgentAuth uses a running ssh agent for ssh repositories.
+func SSHAgentAuth() Auth {
+	return internal.SSHAgentAuth()
+}
+
+// SSHKeyAuth uses a private key file for ssh repositories.
+func SSHKeyAuth(keyFile string, passphrase string) Auth {
+	return internal.SSHKeyAuth(keyFile, passphrase)
+}
+
+// CredentialHelperAuth asks a git credential helper for http(s) credentials.
+// The helper is named as in git's credential.helper setting, while an empty
+// helper uses `git credential fill`, and has no credentials when git finds
+// none.
+func CredentialHelperAuth(helper string) Auth {
+	return internal.CredentialHelperAuth(helper)
+}
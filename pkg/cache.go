@@ -0,0 +1,45 @@
+package scafall
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// DefaultCacheMaxAge is how long a cached branch or tag is used before it is
+// fetched again.
+const DefaultCacheMaxAge = time.Hour
+
+// CacheEntry describes a template checkout held in the cache.
+type CacheEntry = internal.CacheEntry
+
+// DefaultCacheDir returns the scafall directory within the user cache
+// directory, or an empty string when there is no user cache directory.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "scafall")
+}
+
+// ListCache lists the template checkouts held in the cache at dir.
+func ListCache(dir string) ([]CacheEntry, error) {
+	return internal.Cache{Root: dir}.List()
+}
+
+// PruneCache removes template checkouts from the cache at dir that have not
+// been used within olderThan.  Checkouts in use by another process are kept.
+// The removed checkouts are returned.
+func PruneCache(dir string, olderThan time.Duration) ([]CacheEntry, error) {
+	return internal.Cache{Root: dir}.Prune(olderThan)
+}
+
+// ClearCache removes all template checkouts from the cache at dir, except those
+// in use by another process.  A dir holding files that do not belong to a
+// cache is refused.
+func ClearCache(dir string) error {
+	return internal.Cache{Root: dir}.Clear()
+}
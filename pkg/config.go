@@ -0,0 +1,70 @@
+package scafall
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// Config holds the settings of a user and their answers to the prompts of
+// all templates, or of the template at a URL.  See ReadConfig.
+type Config = internal.Config
+
+// TemplateConfig holds the answers of a user for the template at URL.
+type TemplateConfig = internal.TemplateConfig
+
+// DefaultConfigFile returns config.toml in the scafall directory within the
+// user config directory, $XDG_CONFIG_HOME/scafall on Linux, or an empty
+// string when there is no user config directory.
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "scafall", "config.toml")
+}
+
+// ReadConfig reads the TOML Config at path.  A missing file is an empty
+// Config.
+func ReadConfig(path string) (Config, error) {
+	if path == "" {
+		return Config{}, nil
+	}
+	return internal.ReadConfig(path)
+}
+
+// Use the settings and answers of config.  The conflict policy and cache
+// settings of config are applied, while its answers are offered as the
+// defaults of prompts, or answer them when fixed, below any Arguments.  The
+// OutputPath of config is left to the caller.
+func WithConfig(config Config) Option {
+	return func(s *Scafall) {
+		s.Config = config
+		if config.OnConflict != "" {
+			s.ConflictPolicy = config.OnConflict
+		}
+		if config.CacheDir != nil {
+			s.CacheDir = *config.CacheDir
+		}
+		if config.CacheMaxAge != 0 {
+			s.CacheMaxAge = config.CacheMaxAge
+		}
+		if config.Offline {
+			s.Offline = true
+		}
+	}
+}
+
+// The input to a template from arguments and the answers in the Config for
+// the template URL.
+func (s *Scafall) input(arguments map[string]string) internal.Input {
+	defaults, fixed := s.Config.TemplateAnswers(s.URL)
+	return internal.Input{
+		Arguments:   arguments,
+		Defaults:    defaults,
+		Fixed:       fixed,
+		Interactive: !s.NonInteractive,
+		Prompter:    s.Prompter,
+	}
+}
@@ -0,0 +1,30 @@
+package scafall
+
+import (
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// ConflictPolicy decides what happens to an existing file in the output that
+// differs from the generated file.
+type ConflictPolicy = internal.ConflictPolicy
+
+// ConflictError lists every existing file that conflicts with the project.
+type ConflictError = internal.ConflictError
+
+const (
+	// Fail without writing any file, listing all conflicting files
+	ConflictFail = internal.ConflictFail
+	// Keep the existing file
+	ConflictSkip = internal.ConflictSkip
+	// Replace the existing file
+	ConflictOverwrite = internal.ConflictOverwrite
+	// Show a diff of each conflicting file and ask what to do
+	ConflictPrompt = internal.ConflictPrompt
+	// Keep the existing file with a .bak suffix and replace it
+	ConflictBackup = internal.ConflictBackup
+)
+
+// ParseConflictPolicy returns the ConflictPolicy with the given name.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	return internal.ParseConflictPolicy(name)
+}
@@ -0,0 +1,125 @@
+package scafall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// Prompt is a question asked by a template, as declared in prompts.toml.
+type Prompt = internal.Prompt
+
+// Rule is a validation rule of a Prompt.
+type Rule = internal.Rule
+
+// Variable is a value computed by a template from the answers to its prompts.
+type Variable = internal.Variable
+
+// Description of a template: the project Templates of a collection, or the
+// Prompts and Variables of a single template.  Defaults that are templates
+// are left unevaluated.
+type Description struct {
+	Templates []string   `json:"templates,omitempty"`
+	Prompts   []Prompt   `json:"prompts"`
+	Variables []Variable `json:"variables"`
+}
+
+// Describe the template, for example to present it in another tool.
+func (s *Scafall) Describe() (Description, error) {
+	defer s.removeClone()
+	inFs, err := s.templateFS()
+	if err != nil {
+		return Description{}, err
+	}
+	if isCollection, choices := internal.IsCollection(inFs); isCollection {
+		return Description{Templates: choices, Prompts: []Prompt{}, Variables: []Variable{}}, nil
+	}
+
+	template, err := promptTemplate(inFs)
+	if err != nil {
+		return Description{}, err
+	}
+	description := Description{Prompts: template.Arguments(), Variables: template.Variables()}
+	if description.Prompts == nil {
+		description.Prompts = []Prompt{}
+	}
+	if description.Variables == nil {
+		description.Variables = []Variable{}
+	}
+	return description, nil
+}
+
+// String describes the template for a reader, with the description, help and
+// examples of each prompt.
+func (d Description) String() string {
+	out := strings.Builder{}
+	if len(d.Templates) > 0 {
+		out.WriteString("templates available in collection\n")
+		for _, t := range d.Templates {
+			fmt.Fprintf(&out, "\t%s\n", t)
+		}
+		return out.String()
+	}
+
+	out.WriteString("arguments offered by template\n")
+	for _, p := range d.Prompts {
+		fmt.Fprintf(&out, "\t%s\n", argumentSummary(p))
+		details := []string{}
+		if p.Description != "" {
+			details = append(details, p.Description)
+		}
+		if p.Help != "" {
+			details = append(details, strings.Split(p.Help, "\n")...)
+		}
+		if len(p.Examples) > 0 {
+			details = append(details, "examples: "+strings.Join(p.Examples, ", "))
+		}
+		for _, choice := range p.Choices {
+			if choice.Description != "" {
+				details = append(details, fmt.Sprintf("%s: %s", choice.Value, choice.Description))
+			}
+		}
+		for _, line := range details {
+			fmt.Fprintf(&out, "\t\t%s\n", line)
+		}
+	}
+	if len(d.Variables) > 0 {
+		out.WriteString("variables computed by template\n")
+		for _, v := range d.Variables {
+			fmt.Fprintf(&out, "\t%s\n", variableSummary(v))
+		}
+	}
+	return out.String()
+}
+
+// Summarise a prompt as Name (default: X), or Name=a, b (select, default: a)
+// when it has choices, giving the type of prompts other than text and listing
+// the label of a choice beside its value as a [label].  Defaults that depend
+// on other answers are left unevaluated.
+func argumentSummary(p Prompt) string {
+	p = internal.StaticPrompt(p)
+	kind := ""
+	if p.Type != "" && p.Type != internal.TypeString {
+		kind = p.Type + ", "
+	} else if len(p.Choices) > 0 {
+		kind = "select, "
+	}
+	if len(p.Choices) == 0 {
+		return fmt.Sprintf("%s (%sdefault: %s)", p.Name, kind, internal.FormatValue(p.Default))
+	}
+
+	choices := make([]string, len(p.Choices))
+	for i, choice := range p.Choices {
+		choices[i] = choice.String()
+	}
+	defaultValue := internal.FormatValue(p.Default)
+	if p.Default == nil && p.Type != internal.TypeMultiSelect {
+		defaultValue = p.Choices[0].Value
+	}
+	return fmt.Sprintf("%s=%s (%sdefault: %s)", p.Name, strings.Join(choices, ", "), kind, defaultValue)
+}
+
+func variableSummary(v Variable) string {
+	return fmt.Sprintf("%s = %s", v.Name, v.Value)
+}
@@ -1,5 +1,9 @@
 package scafall
 
+import (
+	"github.com/go-git/go-billy/v5/memfs"
+)
+
 // Create a new project from a project template
 func ExampleScafall_Scaffold() {
 	s, _ := NewScafall("http://github.com/AidanDelaney/scafall-python-eg.git",
@@ -19,3 +23,13 @@ func ExampleScafall_Scaffold_arguments() {
 	// User is not prompted for PythonVersion
 	s.Scaffold()
 }
+
+func ExampleScafall_Scaffold_memory() {
+	outputFS := memfs.New()
+	s, _ := NewScafall("http://github.com/AidanDelaney/scafall-python-eg.git",
+		WithOutputFS(outputFS))
+
+	// The project is rendered in memory and can be inspected before it is
+	// written to disk
+	s.Scaffold()
+}
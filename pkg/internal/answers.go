@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ArgEnvPrefix starts the name of an environment variable that gives an
+// argument, such as SCAFALL_ARG_ProjectName.
+const ArgEnvPrefix string = "SCAFALL_ARG_"
+
+// EnvArguments are the arguments given by environment variables in environ,
+// a list of key=value pairs.  The name of an argument follows ArgEnvPrefix and
+// is matched to the name of a prompt exactly, including its case.
+func EnvArguments(environ []string) map[string]string {
+	arguments := map[string]string{}
+	for _, pair := range environ {
+		key, value, ok := strings.Cut(pair, "=")
+		if ok && strings.HasPrefix(key, ArgEnvPrefix) && len(key) > len(ArgEnvPrefix) {
+			arguments[strings.TrimPrefix(key, ArgEnvPrefix)] = value
+		}
+	}
+	return arguments
+}
+
+// ReadAnswers reads the answers to prompts from a TOML, YAML or JSON
+// document, chosen by the extension of name.  When name has no known
+// extension, such as for stdin, the document is read as TOML and then as YAML,
+// which includes JSON.  Each answer is formatted as an argument, so that lists
+// and bools are parsed into the type of their prompt.
+func ReadAnswers(r io.Reader, name string) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".toml":
+		_, err = toml.Decode(string(data), &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	default:
+		if _, tomlErr := toml.Decode(string(data), &values); tomlErr != nil {
+			values = map[string]interface{}{}
+			err = yaml.Unmarshal(data, &values)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("answers %s must be a TOML, YAML or JSON table", name))
+	}
+
+	names := make([]string, 0, len(values))
+	for key := range values {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	answers := map[string]string{}
+	for _, key := range names {
+		if !isScalar(values[key], true) {
+			return nil, fmt.Errorf("answer %s must be a string, number, bool or list of them", key)
+		}
+		answers[key] = FormatValue(values[key])
+	}
+	return answers, nil
+}
+
+// A string, number or bool, or a list of them when list is set.
+func isScalar(value interface{}, list bool) bool {
+	switch v := value.(type) {
+	case map[string]interface{}, []map[string]interface{}:
+		return false
+	case []interface{}:
+		if !list {
+			return false
+		}
+		for _, item := range v {
+			if !isScalar(item, false) {
+				return false
+			}
+		}
+	}
+	return true
+}
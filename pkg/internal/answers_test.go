@@ -0,0 +1,50 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testReadAnswers(t *testing.T, when spec.G, it spec.S) {
+	expected := map[string]string{"Name": "svc", "UseDocker": "true", "Port": "8080", "Features": "logging,tracing"}
+	type TestCase struct {
+		name    string
+		content string
+	}
+	testCases := []TestCase{
+		{"answers.toml", "Name = \"svc\"\nUseDocker = true\nPort = 8080\nFeatures = [\"logging\", \"tracing\"]\n"},
+		{"answers.yaml", "Name: svc\nUseDocker: true\nPort: 8080\nFeatures:\n  - logging\n  - tracing\n"},
+		{"answers.json", `{"Name": "svc", "UseDocker": true, "Port": 8080, "Features": ["logging", "tracing"]}`},
+		{"", "Name = \"svc\"\nUseDocker = true\nPort = 8080\nFeatures = [\"logging\", \"tracing\"]\n"},
+		{"", "Name: svc\nUseDocker: true\nPort: 8080\nFeatures: [logging, tracing]\n"},
+		{"", `{"Name": "svc", "UseDocker": true, "Port": 8080, "Features": ["logging", "tracing"]}`},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("reads "+testCase.content, func() {
+			answers, err := internal.ReadAnswers(strings.NewReader(testCase.content), testCase.name)
+			require.Nil(t, err)
+			require.Equal(t, expected, answers)
+		})
+	}
+
+	it("rejects tables of answers", func() {
+		_, err := internal.ReadAnswers(strings.NewReader("[Database]\nName = \"db\"\n"), "answers.toml")
+		require.EqualError(t, err, "answer Database must be a string, number, bool or list of them")
+	})
+
+	it("rejects documents that are not tables", func() {
+		_, err := internal.ReadAnswers(strings.NewReader("- a\n- b\n"), "answers.yaml")
+		require.ErrorContains(t, err, "answers answers.yaml must be a TOML, YAML or JSON table")
+	})
+
+	it("reads arguments from the environment", func() {
+		arguments := internal.EnvArguments([]string{"HOME=/root", "SCAFALL_ARG_ProjectName=svc", "SCAFALL_ARG_PORT=8080=x", "SCAFALL_ARG_="})
+		require.Equal(t, map[string]string{"ProjectName": "svc", "PORT": "8080=x"}, arguments)
+	})
+}
@@ -0,0 +1,295 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ArchiveExtensions = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+	// Limits guarding against decompression bombs
+	MaxArchiveSize  int64 = 1 << 30
+	MaxArchiveFiles       = 10000
+)
+
+// IsArchive reports whether url names a tar or zip archive.
+func IsArchive(url string) bool {
+	name := strings.ToLower(strings.SplitN(url, "?", 2)[0])
+	for _, ext := range ArchiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// FetchArchive extracts the archive at url, a local path or http(s) URL, into
+// dir.  When checksum is set the archive must have that sha256 digest, given
+// in hex and optionally prefixed by "sha256:".  A single top-level directory
+// in the archive is stripped, as found in release tarballs.
+func FetchArchive(url string, checksum string, dir string) error {
+	archive, err := os.CreateTemp("", "scafall-archive")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if isHTTPURL(url) {
+		err = download(url, archive)
+	} else {
+		err = copyFile(url, archive)
+	}
+	if err != nil {
+		return err
+	}
+
+	if checksum != "" {
+		if err := verifyChecksum(archive, checksum); err != nil {
+			return err
+		}
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	extractDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(dir)), "extract")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractDir)
+
+	name := strings.ToLower(strings.SplitN(url, "?", 2)[0])
+	if strings.HasSuffix(name, ".zip") {
+		info, err := archive.Stat()
+		if err != nil {
+			return err
+		}
+		err = extractZip(archive, info.Size(), extractDir)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to extract %s", url))
+		}
+	} else {
+		var r io.Reader = archive
+		if !strings.HasSuffix(name, ".tar") {
+			gz, err := gzip.NewReader(archive)
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to extract %s", url))
+			}
+			defer gz.Close()
+			r = gz
+		}
+		if err := extractTar(r, extractDir); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to extract %s", url))
+		}
+	}
+
+	return moveContents(stripTopLevel(extractDir), dir)
+}
+
+func download(url string, w io.Writer) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to download %s", url))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	return copyLimited(w, resp.Body)
+}
+
+func copyFile(path string, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return copyLimited(w, f)
+}
+
+func copyLimited(w io.Writer, r io.Reader) error {
+	n, err := io.Copy(w, io.LimitReader(r, MaxArchiveSize+1))
+	if err != nil {
+		return err
+	}
+	if n > MaxArchiveSize {
+		return fmt.Errorf("archive exceeds maximum size of %d bytes", MaxArchiveSize)
+	}
+	return nil
+}
+
+func verifyChecksum(f *os.File, checksum string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	expected := strings.ToLower(strings.TrimPrefix(checksum, "sha256:"))
+	actual := hex.EncodeToString(h.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("archive checksum mismatch: expected sha256:%s, got sha256:%s", expected, actual)
+	}
+	return nil
+}
+
+// Resolve the path of an archive entry within dir, rejecting entries that
+// would be written outside of dir.
+func entryPath(dir string, name string) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if filepath.IsAbs(filepath.FromSlash(name)) || (target != dir && !strings.HasPrefix(target, dir+string(os.PathSeparator))) {
+		return "", fmt.Errorf("archive entry %s is outside of the archive", name)
+	}
+	return target, nil
+}
+
+// Track the number of entries and extracted bytes of an archive.
+type extractLimits struct {
+	files int
+	size  int64
+}
+
+func (l *extractLimits) add() error {
+	l.files++
+	if l.files > MaxArchiveFiles {
+		return fmt.Errorf("archive contains more than %d files", MaxArchiveFiles)
+	}
+	return nil
+}
+
+func (l *extractLimits) write(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(r, MaxArchiveSize-l.size+1))
+	l.size += n
+	if err != nil {
+		return err
+	}
+	if l.size > MaxArchiveSize {
+		return fmt.Errorf("archive expands beyond maximum size of %d bytes", MaxArchiveSize)
+	}
+	return nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	limits := extractLimits{}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := limits.add(); err != nil {
+			return err
+		}
+		target, err := entryPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := limits.write(target, tr, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+		default:
+			return fmt.Errorf("archive entry %s is not a regular file or directory", header.Name)
+		}
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, dir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	limits := extractLimits{}
+	for _, file := range zr.File {
+		if err := limits.add(); err != nil {
+			return err
+		}
+		target, err := entryPath(dir, file.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = limits.write(target, rc, mode)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry %s is not a regular file or directory", file.Name)
+		}
+	}
+	return nil
+}
+
+// Return the single top-level directory of dir, or dir itself.
+func stripTopLevel(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+	return filepath.Join(dir, entries[0].Name())
+}
+
+func moveContents(src string, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
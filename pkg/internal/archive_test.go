@@ -0,0 +1,136 @@
+package internal_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		require.Nil(t, err)
+		_, err = tw.Write([]byte(content))
+		require.Nil(t, err)
+	}
+	require.Nil(t, tw.Close())
+	require.Nil(t, gz.Close())
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.Nil(t, err)
+		_, err = w.Write([]byte(content))
+		require.Nil(t, err)
+	}
+	require.Nil(t, zw.Close())
+	return buf.Bytes()
+}
+
+func testArchive(t *testing.T, when spec.G, it spec.S) {
+	template := map[string]string{
+		"template-v1/prompts.toml":          "",
+		"template-v1/{{.Foo}}/{{.Foo}}.txt": "{{.Foo}}",
+	}
+
+	when("extracting an archive", func() {
+		type TestCase struct {
+			name    string
+			archive func(*testing.T, map[string]string) []byte
+		}
+		testCases := []TestCase{
+			{"template.tgz", tarGz},
+			{"template.tar.gz", tarGz},
+			{"template.zip", zipped},
+		}
+
+		for _, testCase := range testCases {
+			testCase := testCase
+			it("extracts a local "+testCase.name, func() {
+				archive := filepath.Join(t.TempDir(), testCase.name)
+				require.Nil(t, os.WriteFile(archive, testCase.archive(t, template), 0600))
+				require.True(t, internal.IsArchive(archive))
+
+				outputDir := t.TempDir()
+				err := internal.FetchArchive(archive, "", outputDir)
+				require.Nil(t, err)
+
+				content, err := os.ReadFile(filepath.Join(outputDir, "{{.Foo}}", "{{.Foo}}.txt"))
+				require.Nil(t, err)
+				require.Equal(t, "{{.Foo}}", string(content))
+				_, err = os.Stat(filepath.Join(outputDir, "prompts.toml"))
+				require.Nil(t, err)
+			})
+
+			it("downloads "+testCase.name, func() {
+				data := testCase.archive(t, template)
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write(data)
+				}))
+				defer server.Close()
+
+				sum := sha256.Sum256(data)
+				outputDir := t.TempDir()
+				err := internal.FetchArchive(server.URL+"/"+testCase.name, "sha256:"+hex.EncodeToString(sum[:]), outputDir)
+				require.Nil(t, err)
+				_, err = os.Stat(filepath.Join(outputDir, "prompts.toml"))
+				require.Nil(t, err)
+			})
+		}
+
+		it("rejects a checksum mismatch", func() {
+			archive := filepath.Join(t.TempDir(), "template.tgz")
+			require.Nil(t, os.WriteFile(archive, tarGz(t, template), 0600))
+
+			err := internal.FetchArchive(archive, "0000", t.TempDir())
+			require.ErrorContains(t, err, "checksum mismatch")
+		})
+
+		it("rejects entries outside of the archive", func() {
+			parent := t.TempDir()
+			outputDir := filepath.Join(parent, "output")
+			archive := filepath.Join(t.TempDir(), "template.zip")
+			require.Nil(t, os.WriteFile(archive, zipped(t, map[string]string{"../../evil.txt": "evil"}), 0600))
+
+			err := internal.FetchArchive(archive, "", outputDir)
+			require.ErrorContains(t, err, "outside of the archive")
+			_, err = os.Stat(filepath.Join(parent, "evil.txt"))
+			require.True(t, os.IsNotExist(err))
+		})
+
+		it("rejects archives that expand beyond the maximum size", func() {
+			maxSize := internal.MaxArchiveSize
+			internal.MaxArchiveSize = 1024
+			defer func() { internal.MaxArchiveSize = maxSize }()
+
+			archive := filepath.Join(t.TempDir(), "template.tgz")
+			bomb := string(bytes.Repeat([]byte{0}, 4096))
+			require.Nil(t, os.WriteFile(archive, tarGz(t, map[string]string{"bomb.txt": bomb}), 0600))
+
+			err := internal.FetchArchive(archive, "", t.TempDir())
+			require.ErrorContains(t, err, "maximum size")
+		})
+	})
+}
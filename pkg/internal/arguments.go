@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks-community/scafall/pkg/internal/util"
+)
+
+// CheckArguments reports each argument that does not answer a prompt of
+// template, suggesting the name of a prompt when one is close to it.  An
+// argument naming a computed variable is reported as well, since the variable
+// would replace it.
+func CheckArguments(template Template, arguments map[string]string) []string {
+	prompts := []string{}
+	for _, p := range template.Arguments() {
+		prompts = append(prompts, p.Name)
+	}
+	variables := map[string]bool{}
+	for _, v := range template.Variables() {
+		variables[v.Name] = true
+	}
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []string{}
+	for _, name := range names {
+		switch {
+		case variables[name]:
+			problems = append(problems, fmt.Sprintf("argument %s is computed by the template and cannot be set", name))
+		case util.Contains(prompts, name):
+		default:
+			problem := fmt.Sprintf("unknown argument %s", name)
+			if suggestion := closest(name, prompts); suggestion != "" {
+				problem += fmt.Sprintf(", did you mean %s?", suggestion)
+			}
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// Find the candidate closest to name, ignoring case, when it is within a
+// third of the length of name.
+func closest(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+1
+	for _, candidate := range candidates {
+		d := distance(strings.ToLower(name), strings.ToLower(candidate))
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// The Levenshtein distance between a and b, counting the swap of two
+// adjacent characters as a single edit.
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
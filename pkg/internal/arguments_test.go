@@ -0,0 +1,49 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testCheckArguments(t *testing.T, when spec.G, it spec.S) {
+	template := internal.TemplateImpl{
+		TPrompts: internal.Prompts{
+			Prompts: []internal.Prompt{
+				{Name: "PythonVersion", Prompt: "Python version", Choices: internal.NewChoices("3.9", "3.10")},
+				{Name: "ProjectName", Prompt: "Project name"},
+			},
+			Variables: []internal.Variable{{Name: "Package", Value: "{{ .ProjectName | snakecase }}"}},
+		},
+	}
+
+	it("accepts arguments that answer prompts", func() {
+		problems := internal.CheckArguments(template, map[string]string{"PythonVersion": "3.9", "ProjectName": "p"})
+		require.Empty(t, problems)
+	})
+
+	it("suggests the closest prompt for an unknown argument", func() {
+		problems := internal.CheckArguments(template, map[string]string{"PyhtonVersion": "3.9", "projectname": "p", "Other": "o"})
+		require.Equal(t, []string{
+			"unknown argument Other",
+			"unknown argument PyhtonVersion, did you mean PythonVersion?",
+			"unknown argument projectname, did you mean ProjectName?",
+		}, problems)
+	})
+
+	it("reports arguments that set computed variables", func() {
+		problems := internal.CheckArguments(template, map[string]string{"Package": "p"})
+		require.Equal(t, []string{"argument Package is computed by the template and cannot be set"}, problems)
+	})
+
+	it("rejects values that are not choices", func() {
+		prompt := template.TPrompts.Prompts[0]
+		require.Nil(t, internal.Validate(prompt, "3.10", nil))
+		require.EqualError(t, internal.Validate(prompt, "3.8", nil), `PythonVersion must be one of 3.9, 3.10, not "3.8"`)
+		prompt.Type = internal.TypeMultiSelect
+		require.EqualError(t, internal.Validate(prompt, []string{"3.9", "2.7"}, nil), `PythonVersion must be one of 3.9, 3.10, not "2.7"`)
+	})
+}
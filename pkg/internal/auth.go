@@ -0,0 +1,299 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+)
+
+const (
+	UsernameEnv   string = "SCAFALL_GIT_USERNAME"
+	PasswordEnv   string = "SCAFALL_GIT_PASSWORD"
+	TokenEnv      string = "SCAFALL_GIT_TOKEN"
+	HostEnv       string = "SCAFALL_GIT_HOST"
+	NetrcEnv      string = "NETRC"
+	SSHAuthSocket string = "SSH_AUTH_SOCK"
+
+	defaultSSHUser   string = "git"
+	defaultTokenUser string = "oauth2"
+)
+
+// Auth resolves the credentials used to access a git repository.  A nil
+// AuthMethod without an error means Auth has no credentials for url.
+type Auth interface {
+	Method(url string) (transport.AuthMethod, error)
+}
+
+// AuthFunc adapts a function to an Auth.
+type AuthFunc func(url string) (transport.AuthMethod, error)
+
+func (f AuthFunc) Method(url string) (transport.AuthMethod, error) {
+	return f(url)
+}
+
+func isHTTP(e *transport.Endpoint) bool {
+	return e.Protocol == "http" || e.Protocol == "https"
+}
+
+// ChainAuth uses the credentials of the first Auth that has any.
+func ChainAuth(auths ...Auth) Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		for _, a := range auths {
+			if a == nil {
+				continue
+			}
+			method, err := a.Method(url)
+			if err != nil || method != nil {
+				return method, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// DefaultAuth reads credentials from the environment, the netrc file and a
+// running ssh agent.
+func DefaultAuth() Auth {
+	return ChainAuth(EnvAuth(), NetrcAuth(""), SSHAgentAuth())
+}
+
+// BasicAuth uses a username and password for http(s) repositories.
+func BasicAuth(username string, password string) Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || !isHTTP(e) {
+			return nil, nil
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	})
+}
+
+// EnvAuth uses SCAFALL_GIT_USERNAME and SCAFALL_GIT_PASSWORD, or
+// SCAFALL_GIT_TOKEN, for https repositories on the host named by
+// SCAFALL_GIT_HOST, such as github.com or git.example.com:8443.  Without a
+// host the credentials are not used, so they are never sent to the host of
+// another template or over plain http.
+func EnvAuth() Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || e.Protocol != "https" || os.Getenv(HostEnv) == "" || os.Getenv(HostEnv) != endpointHost(e) {
+			return nil, nil
+		}
+		username := os.Getenv(UsernameEnv)
+		if token := os.Getenv(TokenEnv); token != "" {
+			if username == "" {
+				username = defaultTokenUser
+			}
+			return BasicAuth(username, token).Method(url)
+		}
+		if password := os.Getenv(PasswordEnv); password != "" {
+			return BasicAuth(username, password).Method(url)
+		}
+		return nil, nil
+	})
+}
+
+// SSHAgentAuth uses a running ssh agent for ssh repositories.
+func SSHAgentAuth() Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || e.Protocol != "ssh" || os.Getenv(SSHAuthSocket) == "" {
+			return nil, nil
+		}
+		return ssh.NewSSHAgentAuth(sshUser(e))
+	})
+}
+
+// SSHKeyAuth uses a private key file, protected by an optional passphrase,
+// for ssh repositories.
+func SSHKeyAuth(keyFile string, passphrase string) Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || e.Protocol != "ssh" {
+			return nil, nil
+		}
+		method, err := ssh.NewPublicKeysFromFile(sshUser(e), keyFile, passphrase)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to read ssh key %s", keyFile))
+		}
+		return method, nil
+	})
+}
+
+// The host of e, with its port when one is given.
+func endpointHost(e *transport.Endpoint) string {
+	if e.Port != 0 {
+		return fmt.Sprintf("%s:%d", e.Host, e.Port)
+	}
+	return e.Host
+}
+
+func sshUser(e *transport.Endpoint) string {
+	if e.User != "" {
+		return e.User
+	}
+	return defaultSSHUser
+}
+
+// NetrcAuth uses the login and password for the repository host found in a
+// netrc file.  An empty netrcFile uses $NETRC or ~/.netrc.
+func NetrcAuth(netrcFile string) Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || !isHTTP(e) {
+			return nil, nil
+		}
+
+		file := netrcFile
+		if file == "" {
+			file = defaultNetrc()
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if netrcFile == "" && os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to read netrc file %s", file))
+		}
+
+		login, password, ok := parseNetrc(string(content), e.Host)
+		if !ok {
+			return nil, nil
+		}
+		return &http.BasicAuth{Username: login, Password: password}, nil
+	})
+}
+
+func defaultNetrc() string {
+	if file := os.Getenv(NetrcEnv); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// Find the login and password of host, or of the default entry, in a netrc
+// file.
+func parseNetrc(content string, host string) (string, string, bool) {
+	type machine struct {
+		login    string
+		password string
+	}
+	var (
+		found    *machine
+		fallback *machine
+		current  *machine
+	)
+
+	tokens := strings.Fields(content)
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			current = nil
+			if i+1 < len(tokens) {
+				i++
+				if tokens[i] == host && found == nil {
+					found = &machine{}
+					current = found
+				}
+			}
+		case "default":
+			current = nil
+			if fallback == nil {
+				fallback = &machine{}
+				current = fallback
+			}
+		case "login", "password", "account":
+			if i+1 < len(tokens) {
+				i++
+				if current != nil && tokens[i-1] == "login" {
+					current.login = tokens[i]
+				}
+				if current != nil && tokens[i-1] == "password" {
+					current.password = tokens[i]
+				}
+			}
+		case "macdef":
+			// macro definitions run until an empty line, which Fields has
+			// removed, so stop reading
+			current = nil
+			i = len(tokens)
+		}
+	}
+
+	if found == nil {
+		found = fallback
+	}
+	if found == nil {
+		return "", "", false
+	}
+	return found.login, found.password, true
+}
+
+// CredentialHelperAuth asks a git credential helper for the username and
+// password of http(s) repositories.  The helper is named as in git's
+// credential.helper setting; an empty helper uses the helpers configured for
+// git through `git credential fill`.
+func CredentialHelperAuth(helper string) Auth {
+	return AuthFunc(func(url string) (transport.AuthMethod, error) {
+		e, err := transport.NewEndpoint(url)
+		if err != nil || !isHTTP(e) {
+			return nil, nil
+		}
+
+		request := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n", e.Protocol, endpointHost(e), strings.TrimPrefix(e.Path, "/"))
+		if e.User != "" {
+			request += fmt.Sprintf("username=%s\n", e.User)
+		}
+
+		cmd := credentialHelperCommand(helper)
+		cmd.Stdin = strings.NewReader(request + "\n")
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		output, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if helper == "" && errors.As(err, &exitErr) {
+			// git fails when it has no credentials and may not ask for them
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "git credential helper failed")
+		}
+
+		values := map[string]string{}
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+				values[key] = value
+			}
+		}
+		if values["password"] == "" {
+			return nil, nil
+		}
+		return &http.BasicAuth{Username: values["username"], Password: values["password"]}, nil
+	})
+}
+
+func credentialHelperCommand(helper string) *exec.Cmd {
+	switch {
+	case helper == "":
+		return exec.Command("git", "credential", "fill")
+	case strings.HasPrefix(helper, "!"):
+		return exec.Command("sh", "-c", helper[1:]+" get")
+	case filepath.IsAbs(helper):
+		return exec.Command(helper, "get")
+	default:
+		return exec.Command("git", "credential-"+helper, "get")
+	}
+}
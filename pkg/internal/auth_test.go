@@ -0,0 +1,175 @@
+package internal_test
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testAuth(t *testing.T, when spec.G, it spec.S) {
+	const url = "https://git.example.com/org/template.git"
+
+	when("resolving credentials from a netrc file", func() {
+		it("finds the repository host", func() {
+			netrc := filepath.Join(t.TempDir(), "netrc")
+			content := "machine other.example.com login other password secret\n" +
+				"machine git.example.com\n  login duck\n  password quack\n" +
+				"default login anonymous password none\n"
+			require.Nil(t, os.WriteFile(netrc, []byte(content), 0600))
+
+			method, err := internal.NetrcAuth(netrc).Method(url)
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "duck", Password: "quack"}, method)
+
+			method, err = internal.NetrcAuth(netrc).Method("https://unknown.example.com/repo.git")
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "anonymous", Password: "none"}, method)
+
+			method, err = internal.NetrcAuth(netrc).Method("git@git.example.com:org/template.git")
+			require.Nil(t, err)
+			require.Nil(t, method)
+		})
+
+		it("fails when an explicit netrc file is missing", func() {
+			_, err := internal.NetrcAuth(filepath.Join(t.TempDir(), "missing")).Method(url)
+			require.NotNil(t, err)
+		})
+	})
+
+	when("resolving credentials from the environment", func() {
+		it("uses a username and password", func() {
+			t.Setenv(internal.HostEnv, "git.example.com")
+			t.Setenv(internal.UsernameEnv, "duck")
+			t.Setenv(internal.PasswordEnv, "quack")
+			method, err := internal.EnvAuth().Method(url)
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "duck", Password: "quack"}, method)
+		})
+
+		it("uses a token", func() {
+			t.Setenv(internal.HostEnv, "git.example.com")
+			t.Setenv(internal.TokenEnv, "token")
+			method, err := internal.EnvAuth().Method(url)
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "oauth2", Password: "token"}, method)
+		})
+
+		it("only sends credentials over https to their host", func() {
+			t.Setenv(internal.TokenEnv, "token")
+			method, err := internal.EnvAuth().Method(url)
+			require.Nil(t, err)
+			require.Nil(t, method)
+
+			t.Setenv(internal.HostEnv, "git.example.com")
+			for _, other := range []string{
+				"https://other.example.com/org/template.git",
+				"https://git.example.com:8443/org/template.git",
+				"http://git.example.com/org/template.git",
+			} {
+				method, err = internal.EnvAuth().Method(other)
+				require.Nil(t, err)
+				require.Nil(t, method, other)
+			}
+
+			t.Setenv(internal.HostEnv, "git.example.com:8443")
+			method, err = internal.EnvAuth().Method("https://git.example.com:8443/org/template.git")
+			require.Nil(t, err)
+			require.NotNil(t, method)
+		})
+	})
+
+	when("chaining credentials", func() {
+		it("uses the first credentials found", func() {
+			auth := internal.ChainAuth(
+				internal.SSHKeyAuth("missing", ""),
+				internal.BasicAuth("first", "one"),
+				internal.BasicAuth("second", "two"),
+			)
+			method, err := auth.Method(url)
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "first", Password: "one"}, method)
+		})
+	})
+
+	when("using a credential helper", func() {
+		it("bridges the git credential protocol", func() {
+			dir := t.TempDir()
+			request := filepath.Join(dir, "request")
+			helper := filepath.Join(dir, "git-credential-stub")
+			script := "#!/bin/sh\ncat > " + request + "\necho username=duck\necho password=quack\n"
+			require.Nil(t, os.WriteFile(helper, []byte(script), 0700))
+
+			method, err := internal.CredentialHelperAuth(helper).Method(url)
+			require.Nil(t, err)
+			require.Equal(t, &githttp.BasicAuth{Username: "duck", Password: "quack"}, method)
+
+			sent, err := os.ReadFile(request)
+			require.Nil(t, err)
+			require.Equal(t, "protocol=https\nhost=git.example.com\npath=org/template.git\n\n", string(sent))
+		})
+
+		it("has no credentials when git finds none", func() {
+			if _, err := exec.LookPath("git"); err != nil {
+				t.Skip("git is required to fill credentials")
+			}
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", home)
+			t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+			method, err := internal.CredentialHelperAuth("").Method(url)
+			require.Nil(t, err)
+			require.Nil(t, method)
+		})
+	})
+
+	when("cloning a private repository over http", func() {
+		it("authenticates with the resolved credentials", func() {
+			gitPath, err := exec.LookPath("git")
+			if err != nil {
+				t.Skip("git is required to serve a repository over http")
+			}
+			execPath, err := exec.Command(gitPath, "--exec-path").Output()
+			require.Nil(t, err)
+
+			root := t.TempDir()
+			repoDir := filepath.Join(root, "template")
+			repo, err := git.PlainInit(repoDir, false)
+			require.Nil(t, err)
+			commit := commitFile(t, repo, repoDir, "private")
+
+			backend := &cgi.Handler{
+				Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+				Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if user, password, ok := r.BasicAuth(); !ok || user != "duck" || password != "quack" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="scafall"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				backend.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			_, err = internal.Clone(server.URL+"/template", "", nil, t.TempDir())
+			require.NotNil(t, err)
+
+			cloned, err := internal.Clone(server.URL+"/template", "", internal.BasicAuth("duck", "quack"), t.TempDir())
+			require.Nil(t, err)
+			require.Equal(t, commit, cloned)
+		})
+	})
+}
@@ -0,0 +1,510 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+const (
+	sourceFile     string = "source.toml"
+	sourceLockFile string = "source.lock"
+	lockSuffix     string = ".lock"
+	refsDir        string = "refs"
+	stagingDir     string = "tmp"
+	headRefName    string = "HEAD"
+
+	// times a checkout is stored while other processes clear the cache
+	placeAttempts = 3
+)
+
+var (
+	fullSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// the directory name of a source, see hashKey
+	cacheKey = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+// Cache stores checkouts of template repositories on disk.  Each checkout is
+// stored by the hash of its URL and its commit SHA, so a checkout is never
+// modified once written.  Branches and tags are recorded against the commit
+// they resolved to and are refreshed once older than MaxAge.
+//
+// Checkouts are staged in a temporary directory and renamed into place, and
+// ref records are replaced atomically, so processes may share a Cache.  A
+// checkout in use is locked by a lock file beside it, and Prune and Clear
+// only remove checkouts they can lock exclusively, after moving them aside.
+type Cache struct {
+	Root    string
+	Offline bool
+	MaxAge  time.Duration
+}
+
+// CacheEntry describes one cached checkout.
+type CacheEntry struct {
+	URL      string
+	Commit   string
+	Refs     []string
+	Path     string
+	LastUsed time.Time
+}
+
+type cacheSource struct {
+	URL string `toml:"url"`
+}
+
+type cacheRef struct {
+	Ref     string    `toml:"ref"`
+	Commit  string    `toml:"commit"`
+	Fetched time.Time `toml:"fetched"`
+}
+
+func hashKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c Cache) sourceDir(url string) string {
+	return filepath.Join(c.Root, hashKey(url))
+}
+
+// Fetch returns the directory holding a checkout of url at ref and its commit
+// SHA, cloning the repository using auth if there is no fresh checkout in the
+// cache.  The checkout is kept, even by Prune and Clear in other processes,
+// until release is called.
+func (c Cache) Fetch(url string, ref string, auth Auth) (string, string, func(), error) {
+	if commit, ok := c.lookup(url, ref); ok {
+		dir := filepath.Join(c.sourceDir(url), commit)
+		if l, err := use(dir); err == nil {
+			now := time.Now()
+			os.Chtimes(dir, now, now)
+			return dir, commit, l.Unlock, nil
+		}
+	}
+	if c.Offline {
+		if ref == "" {
+			return "", "", nil, fmt.Errorf("template %s is not cached and scafall is offline", url)
+		}
+		return "", "", nil, fmt.Errorf("template %s@%s is not cached and scafall is offline", url, ref)
+	}
+	return c.store(url, ref, auth)
+}
+
+// Hold a shared lock on the checkout in dir, so that it is not removed while
+// it is used.  An error is returned when the checkout has been removed.
+func use(dir string) (*fileLock, error) {
+	l, err := lock(dir+lockSuffix, false, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Find the commit that ref resolves to in the cache.  A commit SHA is always
+// fresh while moving refs are only used when fetched within MaxAge, or when
+// offline.
+func (c Cache) lookup(url string, ref string) (string, bool) {
+	srcDir := c.sourceDir(url)
+	if fullSHA.MatchString(ref) {
+		if _, err := os.Stat(filepath.Join(srcDir, ref)); err == nil {
+			return ref, true
+		}
+		return "", false
+	}
+
+	record, err := readRef(filepath.Join(srcDir, refsDir, refKey(ref)))
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(filepath.Join(srcDir, record.Commit)); err != nil {
+		return "", false
+	}
+	if !c.Offline && time.Since(record.Fetched) > c.MaxAge {
+		return "", false
+	}
+	return record.Commit, true
+}
+
+func (c Cache) store(url string, ref string, auth Auth) (string, string, func(), error) {
+	staging, stagingLock, err := c.stage()
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to create cache directory")
+	}
+	defer func() {
+		os.RemoveAll(staging)
+		stagingLock.Remove()
+	}()
+
+	checkout := filepath.Join(staging, "checkout")
+	commit, err := Clone(url, ref, auth, checkout)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err := os.RemoveAll(filepath.Join(checkout, ".git")); err != nil {
+		return "", "", nil, err
+	}
+
+	// the source directory may be cleared by another process meanwhile
+	dir := filepath.Join(c.sourceDir(url), commit)
+	var l *fileLock
+	for attempt := 1; ; attempt++ {
+		if l, err = c.place(url, checkout, dir, staging); err == nil || attempt == placeAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return "", "", nil, errors.Wrap(err, "failed to store template in cache")
+	}
+
+	record := cacheRef{Ref: ref, Commit: commit, Fetched: time.Now()}
+	if err := writeAtomic(filepath.Join(c.sourceDir(url), refsDir, refKey(ref)), record, staging); err != nil {
+		l.Unlock()
+		return "", "", nil, err
+	}
+	return dir, commit, l.Unlock, nil
+}
+
+// Create a staging directory in the cache.  It is locked, so that Prune and
+// Clear in other processes leave it alone, until the lock is removed.
+func (c Cache) stage() (string, *fileLock, error) {
+	tmpRoot := filepath.Join(c.Root, stagingDir)
+	if err := os.MkdirAll(tmpRoot, 0755); err != nil {
+		return "", nil, err
+	}
+	f, err := os.CreateTemp(tmpRoot, "clone*"+lockSuffix)
+	if err != nil {
+		return "", nil, err
+	}
+	f.Close()
+	l, err := lock(f.Name(), true, true)
+	if err != nil {
+		return "", nil, err
+	}
+	staging := strings.TrimSuffix(f.Name(), lockSuffix)
+	if err := os.Mkdir(staging, 0755); err != nil {
+		l.Remove()
+		return "", nil, err
+	}
+	return staging, l, nil
+}
+
+// Move checkout into dir, returning a shared lock on it.  The source is locked
+// meanwhile, so that Clear does not remove its directory.
+func (c Cache) place(url string, checkout string, dir string, staging string) (*fileLock, error) {
+	srcDir := filepath.Dir(dir)
+	if err := os.MkdirAll(filepath.Join(srcDir, refsDir), 0755); err != nil {
+		return nil, err
+	}
+	sourceLock, err := lock(filepath.Join(srcDir, sourceLockFile), false, true)
+	if err != nil {
+		return nil, err
+	}
+	defer sourceLock.Unlock()
+	if err := writeAtomic(filepath.Join(srcDir, sourceFile), cacheSource{URL: url}, staging); err != nil {
+		return nil, err
+	}
+
+	l, err := lock(dir+lockSuffix, false, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(checkout, dir); err != nil {
+		// another process may have stored the same commit first
+		if _, statErr := os.Stat(dir); statErr != nil {
+			l.Unlock()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func refKey(ref string) string {
+	if ref == "" {
+		ref = headRefName
+	}
+	return hashKey(ref) + ".toml"
+}
+
+func readRef(path string) (cacheRef, error) {
+	record := cacheRef{}
+	_, err := toml.DecodeFile(path, &record)
+	return record, err
+}
+
+// Write value as TOML to a temporary file in tmpDir and rename it over path,
+// so readers never observe a partially written file.
+func writeAtomic(path string, value interface{}, tmpDir string) error {
+	f, err := os.CreateTemp(tmpDir, "record")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = toml.NewEncoder(f).Encode(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// List all checkouts held in the cache.
+func (c Cache) List() ([]CacheEntry, error) {
+	sources, err := os.ReadDir(c.Root)
+	if os.IsNotExist(err) {
+		return []CacheEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []CacheEntry{}
+	for _, source := range sources {
+		if !source.IsDir() || source.Name() == stagingDir {
+			continue
+		}
+		srcDir := filepath.Join(c.Root, source.Name())
+		src := cacheSource{}
+		if _, err := toml.DecodeFile(filepath.Join(srcDir, sourceFile), &src); err != nil {
+			continue
+		}
+
+		refs := map[string][]string{}
+		records, _ := os.ReadDir(filepath.Join(srcDir, refsDir))
+		for _, r := range records {
+			record, err := readRef(filepath.Join(srcDir, refsDir, r.Name()))
+			if err != nil {
+				continue
+			}
+			name := record.Ref
+			if name == "" {
+				name = headRefName
+			}
+			refs[record.Commit] = append(refs[record.Commit], name)
+		}
+
+		commits, err := os.ReadDir(srcDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			if !commit.IsDir() || !fullSHA.MatchString(commit.Name()) {
+				continue
+			}
+			info, err := commit.Info()
+			if err != nil {
+				continue
+			}
+			names := refs[commit.Name()]
+			sort.Strings(names)
+			entries = append(entries, CacheEntry{
+				URL:      src.URL,
+				Commit:   commit.Name(),
+				Refs:     names,
+				Path:     filepath.Join(srcDir, commit.Name()),
+				LastUsed: info.ModTime(),
+			})
+		}
+	}
+	return entries, nil
+}
+
+// Prune removes checkouts not used within olderThan along with abandoned
+// staging directories.  Checkouts in use by any process are kept.  The
+// removed entries are returned.
+func (c Cache) Prune(olderThan time.Duration) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []CacheEntry{}
+	for _, entry := range entries {
+		if time.Since(entry.LastUsed) <= olderThan {
+			continue
+		}
+		ok, err := c.remove(entry.Path)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			removed = append(removed, entry)
+		}
+	}
+	return removed, c.removeStaging(olderThan)
+}
+
+// Clear removes every checkout from the cache, except those in use by any
+// process, along with abandoned staging directories.  Only the directories of
+// the cache are removed, and a Root holding anything else is refused, so that
+// a mistaken Root is left as it is.
+func (c Cache) Clear() error {
+	if c.Root == "" {
+		return nil
+	}
+	items, err := os.ReadDir(c.Root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !item.IsDir() || (item.Name() != stagingDir && !cacheKey.MatchString(item.Name())) {
+			return fmt.Errorf("%s does not look like a template cache, it contains %s", c.Root, item.Name())
+		}
+	}
+
+	for _, item := range items {
+		if item.Name() == stagingDir {
+			continue
+		}
+		if err := c.clearSource(filepath.Join(c.Root, item.Name())); err != nil {
+			return err
+		}
+	}
+	return c.removeStaging(0)
+}
+
+// Remove the checkouts in srcDir that are not in use, and srcDir itself once
+// it holds no checkout.  A directory without a source file is being stored,
+// and is left alone.
+func (c Cache) clearSource(srcDir string) error {
+	if _, err := os.Stat(filepath.Join(srcDir, sourceFile)); err != nil {
+		return nil
+	}
+	sourceLock, err := lock(filepath.Join(srcDir, sourceLockFile), true, false)
+	if err == errLocked {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	items, err := os.ReadDir(srcDir)
+	if err != nil {
+		sourceLock.Unlock()
+		return err
+	}
+	kept := false
+	for _, item := range items {
+		if !item.IsDir() || !fullSHA.MatchString(item.Name()) {
+			continue
+		}
+		removed, err := c.remove(filepath.Join(srcDir, item.Name()))
+		if err != nil {
+			sourceLock.Unlock()
+			return err
+		}
+		kept = kept || !removed
+	}
+	if kept {
+		sourceLock.Unlock()
+		return nil
+	}
+
+	// the lock file is moved aside along with srcDir
+	aside, err := c.moveAside(srcDir)
+	sourceLock.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(aside)
+}
+
+// Remove the checkout in dir unless a process uses it, reporting whether it
+// was removed.  It is moved aside first, so that no process sees a partly
+// removed checkout.
+func (c Cache) remove(dir string) (bool, error) {
+	l, err := lock(dir+lockSuffix, true, false)
+	if err == errLocked {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	aside, err := c.moveAside(dir)
+	if err != nil {
+		l.Unlock()
+		return false, err
+	}
+	l.Remove()
+	return true, os.RemoveAll(aside)
+}
+
+// Rename path into a new directory in the staging directory, returning the new
+// directory to remove.
+func (c Cache) moveAside(path string) (string, error) {
+	tmpRoot := filepath.Join(c.Root, stagingDir)
+	if err := os.MkdirAll(tmpRoot, 0755); err != nil {
+		return "", err
+	}
+	aside, err := os.MkdirTemp(tmpRoot, "remove")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, filepath.Join(aside, filepath.Base(path))); err != nil {
+		os.Remove(aside)
+		return "", err
+	}
+	return aside, nil
+}
+
+// Remove the staging directories, and their lock files, last changed before
+// olderThan that no process is using.
+func (c Cache) removeStaging(olderThan time.Duration) error {
+	tmpRoot := filepath.Join(c.Root, stagingDir)
+	items, err := os.ReadDir(tmpRoot)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// a staging directory and its lock file are removed together
+	changed := map[string]time.Time{}
+	for _, item := range items {
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(item.Name(), lockSuffix)
+		if info.ModTime().After(changed[name]) {
+			changed[name] = info.ModTime()
+		}
+	}
+	for name, modTime := range changed {
+		if time.Since(modTime) <= olderThan {
+			continue
+		}
+		path := filepath.Join(tmpRoot, name)
+		l, err := lock(path+lockSuffix, true, false)
+		if err == errLocked {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = os.RemoveAll(path)
+		l.Remove()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testCache(t *testing.T, when spec.G, it spec.S) {
+	when("fetching templates through the cache", func() {
+		var (
+			repoDir string
+			repo    *git.Repository
+			first   string
+			cache   internal.Cache
+		)
+
+		it.Before(func() {
+			var err error
+			repoDir = t.TempDir()
+			repo, err = git.PlainInit(repoDir, false)
+			require.Nil(t, err)
+			first = commitFile(t, repo, repoDir, "v1")
+			cache = internal.Cache{Root: t.TempDir(), MaxAge: time.Hour}
+		})
+
+		it("reuses a cached checkout", func() {
+			dir, commit, release, err := cache.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+			release()
+			require.Equal(t, first, commit)
+			content, err := os.ReadFile(filepath.Join(dir, "template.txt"))
+			require.Nil(t, err)
+			require.Equal(t, "v1", string(content))
+			_, err = os.Stat(filepath.Join(dir, ".git"))
+			require.True(t, os.IsNotExist(err))
+
+			os.RemoveAll(repoDir)
+			cachedDir, cachedCommit, release, err := cache.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+			release()
+			require.Equal(t, dir, cachedDir)
+			require.Equal(t, first, cachedCommit)
+		})
+
+		it("refreshes a moving ref once it is stale", func() {
+			_, _, release, err := cache.Fetch(repoDir, "master", nil)
+			require.Nil(t, err)
+			release()
+			second := commitFile(t, repo, repoDir, "v2")
+
+			_, commit, release, err := cache.Fetch(repoDir, "master", nil)
+			require.Nil(t, err)
+			release()
+			require.Equal(t, first, commit)
+
+			cache.MaxAge = 0
+			_, commit, release, err = cache.Fetch(repoDir, "master", nil)
+			require.Nil(t, err)
+			release()
+			require.Equal(t, second, commit)
+		})
+
+		it("only uses cached templates when offline", func() {
+			offline := internal.Cache{Root: cache.Root, Offline: true}
+			_, _, _, err := offline.Fetch(repoDir, "", nil)
+			require.NotNil(t, err)
+
+			_, _, release, err := cache.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+			release()
+			_, commit, release, err := offline.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+			release()
+			require.Equal(t, first, commit)
+		})
+
+		it("is shared by concurrent fetches", func() {
+			var wg sync.WaitGroup
+			errs := make([]error, 4)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, _, release, err := cache.Fetch(repoDir, first, nil)
+					if err == nil {
+						release()
+					}
+					errs[i] = err
+				}(i)
+			}
+			wg.Wait()
+			for _, err := range errs {
+				require.Nil(t, err)
+			}
+
+			entries, err := cache.List()
+			require.Nil(t, err)
+			require.Len(t, entries, 1)
+		})
+
+		it("lists, prunes and clears checkouts", func() {
+			_, _, release, err := cache.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+			release()
+
+			entries, err := cache.List()
+			require.Nil(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, repoDir, entries[0].URL)
+			require.Equal(t, first, entries[0].Commit)
+			require.Equal(t, []string{"HEAD"}, entries[0].Refs)
+
+			removed, err := cache.Prune(time.Hour)
+			require.Nil(t, err)
+			require.Len(t, removed, 0)
+			removed, err = cache.Prune(-time.Second)
+			require.Nil(t, err)
+			require.Len(t, removed, 1)
+			entries, err = cache.List()
+			require.Nil(t, err)
+			require.Len(t, entries, 0)
+
+			err = cache.Clear()
+			require.Nil(t, err)
+			items, err := os.ReadDir(cache.Root)
+			require.Nil(t, err)
+			require.Len(t, items, 1)
+			staged, err := os.ReadDir(filepath.Join(cache.Root, "tmp"))
+			require.Nil(t, err)
+			require.Len(t, staged, 0)
+		})
+
+		it("keeps checkouts in use", func() {
+			dir, _, release, err := cache.Fetch(repoDir, "", nil)
+			require.Nil(t, err)
+
+			removed, err := cache.Prune(-time.Second)
+			require.Nil(t, err)
+			require.Len(t, removed, 0)
+			err = cache.Clear()
+			require.Nil(t, err)
+			content, err := os.ReadFile(filepath.Join(dir, "template.txt"))
+			require.Nil(t, err)
+			require.Equal(t, "v1", string(content))
+
+			release()
+			err = cache.Clear()
+			require.Nil(t, err)
+			_, err = os.Stat(dir)
+			require.True(t, os.IsNotExist(err))
+			entries, err := cache.List()
+			require.Nil(t, err)
+			require.Len(t, entries, 0)
+		})
+
+		it("refuses to clear a directory that is not a cache", func() {
+			root := t.TempDir()
+			err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("keep"), 0600)
+			require.Nil(t, err)
+
+			err = internal.Cache{Root: root}.Clear()
+			require.ErrorContains(t, err, "does not look like a template cache")
+			_, err = os.Stat(filepath.Join(root, "notes.txt"))
+			require.Nil(t, err)
+		})
+	})
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Choice is one of the choices of a prompt.  The Label is shown to the user,
+// while the Value is bound in the answers; a choice given in prompts.toml as
+// a plain string is its own label.
+type Choice struct {
+	Label       string `toml:"label,omitempty" json:"label,omitempty"`
+	Value       string `toml:"value" json:"value"`
+	Description string `toml:"description,omitempty" json:"description,omitempty"`
+}
+
+// NewChoices creates a choice for each of values.
+func NewChoices(values ...string) []Choice {
+	choices := make([]Choice, len(values))
+	for i, value := range values {
+		choices[i] = Choice{Value: value}
+	}
+	return choices
+}
+
+// UnmarshalTOML reads a choice from either a string or a table with label,
+// value and description keys.
+func (c *Choice) UnmarshalTOML(data interface{}) error {
+	switch d := data.(type) {
+	case string:
+		*c = Choice{Value: d}
+		return nil
+	case map[string]interface{}:
+		choice := Choice{}
+		for key, value := range d {
+			switch key {
+			case "label":
+				choice.Label = FormatValue(value)
+			case "value":
+				choice.Value = FormatValue(value)
+			case "description":
+				choice.Description = FormatValue(value)
+			default:
+				return fmt.Errorf("choice has unknown key %s", key)
+			}
+		}
+		if _, ok := d["value"]; !ok {
+			return fmt.Errorf("choice %s requires a value", choice.Label)
+		}
+		*c = choice
+		return nil
+	default:
+		return fmt.Errorf("choice must be a string or a table, not %v", data)
+	}
+}
+
+// Display is the text shown to the user for the choice.
+func (c Choice) Display() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return c.Value
+}
+
+// String shows both the value and the label of the choice.
+func (c Choice) String() string {
+	if c.Label == "" || c.Label == c.Value {
+		return c.Value
+	}
+	return fmt.Sprintf("%s [%s]", c.Value, c.Label)
+}
+
+// Labels of choices, as shown to the user.
+func choiceLabels(choices []Choice) []string {
+	labels := make([]string, len(choices))
+	for i, choice := range choices {
+		labels[i] = choice.Display()
+	}
+	return labels
+}
+
+// Values of choices, as bound in the answers.
+func choiceValues(choices []Choice) []string {
+	values := make([]string, len(choices))
+	for i, choice := range choices {
+		values[i] = choice.Value
+	}
+	return values
+}
+
+// Descriptions of choices, shown beside their labels in a selection, or nil
+// when no choice has a description.
+func choiceDescriptions(choices []Choice) []string {
+	for _, choice := range choices {
+		if choice.Description != "" {
+			descriptions := make([]string, len(choices))
+			for i, choice := range choices {
+				descriptions[i] = choice.Description
+			}
+			return descriptions
+		}
+	}
+	return nil
+}
+
+// Find the value of the choice whose value or label is text, preferring
+// values.  Text that matches no choice is returned unchanged.
+func choiceValue(choices []Choice, text string) string {
+	for _, choice := range choices {
+		if choice.Value == text {
+			return text
+		}
+	}
+	for _, choice := range choices {
+		if choice.Label == text {
+			return choice.Value
+		}
+	}
+	return text
+}
+
+// Find the label of the choice whose value or label is text, or "" when text
+// matches no choice.
+func choiceLabel(choices []Choice, text string) string {
+	for _, choice := range choices {
+		if choice.Value == text || choice.Label == text {
+			return choice.Display()
+		}
+	}
+	return ""
+}
+
+// The label, value and description of choice rendered by resolve.
+func resolveChoice(choice Choice, resolve func(string) (string, error)) (Choice, error) {
+	var err error
+	resolved := Choice{}
+	if resolved.Label, err = resolve(choice.Label); err != nil {
+		return Choice{}, err
+	}
+	if resolved.Value, err = resolve(choice.Value); err != nil {
+		return Choice{}, err
+	}
+	if resolved.Description, err = resolve(choice.Description); err != nil {
+		return Choice{}, err
+	}
+	return resolved, nil
+}
+
+// Check that no choice of a multiselect prompt contains the ValueSeparator,
+// which would split the choice in two once its value is given as a string.
+// Choices that are templates are checked once they are resolved.
+func checkSeparator(prompt Prompt) error {
+	if prompt.Type != TypeMultiSelect {
+		return nil
+	}
+	for _, choice := range prompt.Choices {
+		if isTemplatedChoice(choice) {
+			continue
+		}
+		if strings.Contains(choice.Value, ValueSeparator) || strings.Contains(choice.Label, ValueSeparator) {
+			return fmt.Errorf("choice %s of multiselect prompt %s must not contain %q", choice, prompt.Name, ValueSeparator)
+		}
+	}
+	return nil
+}
+
+func isTemplatedChoice(choice Choice) bool {
+	return strings.Contains(choice.Label, "{{") || strings.Contains(choice.Value, "{{") || strings.Contains(choice.Description, "{{")
+}
@@ -0,0 +1,131 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testChoices(t *testing.T, when spec.G, it spec.S) {
+	versions := []internal.Choice{
+		{Label: "Spring Boot 3 (recommended)", Value: "3.2.1", Description: "Requires Java 17"},
+		{Label: "Spring Boot 2", Value: "2.7.18"},
+	}
+
+	when("reading prompts.toml", func() {
+		it("accepts both strings and tables", func() {
+			template, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "SpringBootVersion"
+prompt = "Spring Boot version"
+choices = [
+  { label = "Spring Boot 3 (recommended)", value = "3.2.1", description = "Requires Java 17" },
+  { label = "Spring Boot 2", value = "2.7.18" },
+]
+
+[[prompt]]
+name = "Python"
+prompt = "Python version"
+choices = ["python3.10", "python3.9"]
+`), nil)
+			require.Nil(t, err)
+			require.Equal(t, versions, template.Arguments()[0].Choices)
+			require.Equal(t, internal.NewChoices("python3.10", "python3.9"), template.Arguments()[1].Choices)
+		})
+
+		it("requires the value of a choice", func() {
+			_, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "SpringBootVersion"
+prompt = "Spring Boot version"
+choices = [{ label = "Spring Boot 3" }]
+`), nil)
+			require.ErrorContains(t, err, "choice Spring Boot 3 requires a value")
+		})
+
+		it("rejects a multiselect choice containing a comma", func() {
+			_, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "Cities"
+prompt = "Cities"
+type = "multiselect"
+choices = ["Paris, France", "Rome"]
+`), nil)
+			require.ErrorContains(t, err, `choice Paris, France of multiselect prompt Cities must not contain ","`)
+
+			_, err = internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "Cities"
+prompt = "Cities"
+type = "multiselect"
+choices = [{ label = "Paris, France", value = "paris" }, "Rome"]
+`), nil)
+			require.ErrorContains(t, err, "multiselect prompt Cities must not contain")
+		})
+
+		it("accepts a select choice containing a comma", func() {
+			template, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "City"
+prompt = "City"
+choices = ["Paris, France", "Rome"]
+`), nil)
+			require.Nil(t, err)
+			value, err := internal.ParseValue(template.Arguments()[0], "Paris, France")
+			require.Nil(t, err)
+			require.Equal(t, "Paris, France", value)
+		})
+	})
+
+	when("resolving templated choices", func() {
+		it("rejects a multiselect choice containing a comma", func() {
+			prompt := internal.Prompt{Name: "Cities", Type: internal.TypeMultiSelect, Choices: internal.NewChoices("{{ .Capital }}", "Rome")}
+			_, err := internal.ResolvePrompt(prompt, map[string]interface{}{"Capital": "Paris, France"})
+			require.ErrorContains(t, err, "multiselect prompt Cities must not contain")
+		})
+	})
+
+	when("parsing arguments", func() {
+		it("accepts a value or a label", func() {
+			prompt := internal.Prompt{Name: "SpringBootVersion", Choices: versions}
+			value, err := internal.ParseValue(prompt, "2.7.18")
+			require.Nil(t, err)
+			require.Equal(t, "2.7.18", value)
+			value, err = internal.ParseValue(prompt, "Spring Boot 3 (recommended)")
+			require.Nil(t, err)
+			require.Equal(t, "3.2.1", value)
+
+			prompt.Type = internal.TypeMultiSelect
+			value, err = internal.ParseValue(prompt, "Spring Boot 2, 3.2.1")
+			require.Nil(t, err)
+			require.Equal(t, []string{"2.7.18", "3.2.1"}, value)
+		})
+	})
+
+	when("asking", func() {
+		it("shows labels and binds values", func() {
+			prompt := internal.Prompt{Name: "SpringBootVersion", Prompt: "Spring Boot version", Choices: versions}
+			question := internal.NewQuestion(prompt)
+			template := internal.TemplateImpl{
+				TPrompts:   internal.Prompts{Prompts: []internal.Prompt{prompt}},
+				TQuestions: []*survey.Question{&question},
+			}
+			procedure := func(c expectConsole) {
+				c.ExpectString("Spring Boot 3 (recommended)")
+				c.ExpectString("Spring Boot 2")
+				c.Send("\x1b\x5b\x42")
+				c.SendLine("")
+				c.ExpectEOF()
+			}
+			test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+				return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, procedure, test, map[string]interface{}{"SpringBootVersion": "2.7.18"})
+		})
+	})
+}
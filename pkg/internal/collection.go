@@ -1,20 +1,19 @@
 package internal
 
 import (
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 )
 
 // If there are no top level prompts and some subdirectories contain prompts,
 // then we're dealing with a collection.  Otherwise it's scaffolding with no
 // prompts
-func IsCollection(dir string) (bool, []string) {
-	promptFile := filepath.Join(dir, PromptFile)
-	if _, err := os.Stat(promptFile); err == nil {
+func IsCollection(fsys fs.FS) (bool, []string) {
+	if _, err := fs.Stat(fsys, PromptFile); err == nil {
 		return false, []string{}
 	}
 
-	entries, err := os.ReadDir(dir)
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return false, []string{}
 	}
@@ -22,8 +21,8 @@ func IsCollection(dir string) (bool, []string) {
 	options := []string{}
 	for _, entry := range entries {
 		if entry.IsDir() {
-			promptFile := filepath.Join(dir, entry.Name(), PromptFile)
-			if _, err := os.Stat(promptFile); err == nil {
+			promptFile := path.Join(entry.Name(), PromptFile)
+			if _, err := fs.Stat(fsys, promptFile); err == nil {
 				options = append(options, entry.Name())
 			}
 		}
@@ -45,7 +45,7 @@ func testCollection(t *testing.T, when spec.G, it spec.S) {
 				os.RemoveAll(*collectionDir)
 			})
 			it("detects a collection", func() {
-				collection, options := internal.IsCollection(*collectionDir)
+				collection, options := internal.IsCollection(os.DirFS(*collectionDir))
 				require.True(t, collection)
 				require.Equal(t, options, testCase.templates)
 			})
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Config holds the settings and answers of a user, such as
+//
+//	output_path = "~/src"
+//	on_conflict = "backup"
+//	fixed = ["AuthorName"]
+//
+//	[answers]
+//	AuthorName = "Jane Doe"
+//	License = "MIT"
+//
+//	[[template]]
+//	url = "https://github.com/org/templates.git"
+//	answers = { Org = "org" }
+//
+// Answers replace the defaults of prompts of the same name, so that they are
+// still asked, unless they are listed in Fixed.  The Answers of a template
+// take precedence over the global Answers.  CacheDir is nil when unset, since
+// an empty CacheDir disables caching.
+type Config struct {
+	OutputPath  string                 `toml:"output_path"`
+	OnConflict  ConflictPolicy         `toml:"on_conflict"`
+	CacheDir    *string                `toml:"cache_dir"`
+	CacheMaxAge time.Duration          `toml:"cache_max_age"`
+	Offline     bool                   `toml:"offline"`
+	Answers     map[string]interface{} `toml:"answers"`
+	Fixed       []string               `toml:"fixed"`
+	Templates   []TemplateConfig       `toml:"template"`
+}
+
+// TemplateConfig holds the answers of a user for the template at URL.
+type TemplateConfig struct {
+	URL     string                 `toml:"url"`
+	Answers map[string]interface{} `toml:"answers"`
+	Fixed   []string               `toml:"fixed"`
+}
+
+// ReadConfig reads the Config at path.  A missing file is an empty Config.
+func ReadConfig(path string) (Config, error) {
+	config := Config{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if _, err := toml.Decode(string(data), &config); err != nil {
+		return Config{}, errors.Wrap(err, fmt.Sprintf("config %s does not match required format", path))
+	}
+	if config.OnConflict != "" {
+		if _, err := ParseConflictPolicy(string(config.OnConflict)); err != nil {
+			return Config{}, errors.Wrap(err, fmt.Sprintf("config %s", path))
+		}
+	}
+	config.OutputPath = expandHome(config.OutputPath)
+	if config.CacheDir != nil {
+		cacheDir := expandHome(*config.CacheDir)
+		config.CacheDir = &cacheDir
+	}
+	for _, t := range config.Templates {
+		if t.URL == "" {
+			return Config{}, fmt.Errorf("config %s contains template without url", path)
+		}
+	}
+	return config, nil
+}
+
+// TemplateAnswers splits the answers for the template at url into the
+// defaults of prompts and the fixed answers, which are not asked.
+func (c Config) TemplateAnswers(url string) (map[string]string, map[string]string) {
+	answers := map[string]interface{}{}
+	fixed := map[string]bool{}
+	for name, value := range c.Answers {
+		answers[name] = value
+	}
+	for _, name := range c.Fixed {
+		fixed[name] = true
+	}
+	for _, t := range c.Templates {
+		if sameURL(t.URL, url) {
+			for name, value := range t.Answers {
+				answers[name] = value
+			}
+			for _, name := range t.Fixed {
+				fixed[name] = true
+			}
+		}
+	}
+
+	defaults, fixedAnswers := map[string]string{}, map[string]string{}
+	for name, value := range answers {
+		if fixed[name] {
+			fixedAnswers[name] = FormatValue(value)
+		} else {
+			defaults[name] = FormatValue(value)
+		}
+	}
+	return defaults, fixedAnswers
+}
+
+// Replace a leading ~ in path with the home directory of the user.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// Compare template urls, ignoring a trailing slash or .git suffix.
+func sameURL(a, b string) bool {
+	normalise := func(url string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	}
+	return normalise(a) == normalise(b)
+}
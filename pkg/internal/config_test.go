@@ -0,0 +1,106 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testConfig(t *testing.T, when spec.G, it spec.S) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	when("reading a config", func() {
+		it("reads settings and answers", func() {
+			config, err := internal.ReadConfig(write(`
+output_path = "/src"
+on_conflict = "backup"
+cache_dir = ""
+cache_max_age = "24h"
+fixed = ["AuthorName"]
+
+[answers]
+AuthorName = "Jane Doe"
+License = "MIT"
+
+[[template]]
+url = "https://github.com/org/templates.git"
+answers = { Org = "org", Features = ["logging"] }
+`))
+			require.Nil(t, err)
+			require.Equal(t, "/src", config.OutputPath)
+			require.Equal(t, internal.ConflictBackup, config.OnConflict)
+			require.Equal(t, "", *config.CacheDir)
+			require.Equal(t, 24*time.Hour, config.CacheMaxAge)
+			require.Equal(t, "https://github.com/org/templates.git", config.Templates[0].URL)
+		})
+
+		it("treats a missing config as empty", func() {
+			config, err := internal.ReadConfig(filepath.Join(t.TempDir(), "config.toml"))
+			require.Nil(t, err)
+			require.Equal(t, internal.Config{}, config)
+		})
+
+		it("rejects an unknown conflict policy", func() {
+			_, err := internal.ReadConfig(write(`on_conflict = "merge"`))
+			require.ErrorContains(t, err, "unknown conflict policy merge")
+		})
+	})
+
+	when("answering a template", func() {
+		config := internal.Config{
+			Answers: map[string]interface{}{"AuthorName": "Jane Doe", "License": "MIT", "Org": "personal"},
+			Fixed:   []string{"AuthorName"},
+			Templates: []internal.TemplateConfig{
+				{URL: "https://github.com/org/templates", Answers: map[string]interface{}{"Org": "org", "Port": int64(8080)}, Fixed: []string{"Org"}},
+			},
+		}
+
+		it("prefers the answers of the template", func() {
+			defaults, fixed := config.TemplateAnswers("https://github.com/org/templates.git")
+			require.Equal(t, map[string]string{"License": "MIT", "Port": "8080"}, defaults)
+			require.Equal(t, map[string]string{"AuthorName": "Jane Doe", "Org": "org"}, fixed)
+		})
+
+		it("uses the global answers for other templates", func() {
+			defaults, fixed := config.TemplateAnswers("https://github.com/other/template.git")
+			require.Equal(t, map[string]string{"License": "MIT", "Org": "personal"}, defaults)
+			require.Equal(t, map[string]string{"AuthorName": "Jane Doe"}, fixed)
+		})
+	})
+
+	when("presetting a template", func() {
+		it("replaces defaults and answers fixed prompts", func() {
+			template, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "AuthorName"
+prompt = "Author"
+
+[[prompt]]
+name = "License"
+prompt = "License"
+default = "Apache-2.0"
+
+[[prompt]]
+name = "Org"
+prompt = "Organisation"
+`), map[string]string{"Org": "argument"})
+			require.Nil(t, err)
+
+			preset := template.Preset(map[string]string{"License": "MIT", "Other": "x"}, map[string]string{"AuthorName": "Jane Doe", "Org": "fixed"})
+			require.Equal(t, "MIT", preset.Arguments()[1].Default)
+			answers, err := preset.Defaults()
+			require.Nil(t, err)
+			require.Equal(t, map[string]interface{}{"AuthorName": "Jane Doe", "License": "MIT", "Org": "argument"}, answers)
+		})
+	})
+}
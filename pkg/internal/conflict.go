@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// ConflictPolicy decides what happens to a file in the output folder that
+// would be replaced by a different generated file.
+type ConflictPolicy string
+
+const (
+	ConflictFail      ConflictPolicy = "fail"
+	ConflictSkip      ConflictPolicy = "skip"
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictPrompt    ConflictPolicy = "prompt"
+	ConflictBackup    ConflictPolicy = "backup"
+
+	backupSuffix string = ".bak"
+
+	// bytes searched for a NUL byte to tell binary content, as git does
+	binarySniffLen = 8000
+)
+
+var ConflictPolicies = []ConflictPolicy{ConflictFail, ConflictSkip, ConflictOverwrite, ConflictPrompt, ConflictBackup}
+
+// ParseConflictPolicy validates the name of a ConflictPolicy.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	for _, p := range ConflictPolicies {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	names := make([]string, len(ConflictPolicies))
+	for i, p := range ConflictPolicies {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("unknown conflict policy %s, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Conflict is a generated file whose path already exists in the output
+// folder with different content.
+type Conflict struct {
+	Path     string
+	Existing []byte
+	Rendered []byte
+}
+
+// Diff of the existing and rendered content of the conflicting file, or a
+// note that they differ when either is binary.
+func (c Conflict) Diff() string {
+	if isBinary(c.Existing) || isBinary(c.Rendered) {
+		return fmt.Sprintf("binary files differ: %s\n", c.Path)
+	}
+	return UnifiedDiff("a/"+c.Path, "b/"+c.Path, string(c.Existing), string(c.Rendered))
+}
+
+// Content is binary when it holds a NUL byte within its first bytes.
+func isBinary(content []byte) bool {
+	if len(content) > binarySniffLen {
+		content = content[:binarySniffLen]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// ConflictError lists every conflicting path.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("output folder already contains %d conflicting files:\n\t%s", len(e.Paths), strings.Join(e.Paths, "\n\t"))
+}
+
+// FindConflicts compares the rendered project in staged with target.  Files
+// with identical content are not conflicts.
+func FindConflicts(staged billy.Filesystem, target billy.Filesystem) ([]Conflict, error) {
+	conflicts := []Conflict{}
+	err := util.Walk(staged, "/", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		existing, statErr := target.Stat(rel)
+		if statErr != nil {
+			return nil
+		}
+
+		rendered, err := util.ReadFile(staged, rel)
+		if err != nil {
+			return err
+		}
+		if existing.IsDir() {
+			conflicts = append(conflicts, Conflict{Path: rel, Rendered: rendered})
+			return nil
+		}
+		content, err := util.ReadFile(target, rel)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(content, rendered) {
+			conflicts = append(conflicts, Conflict{Path: rel, Existing: content, Rendered: rendered})
+		}
+		return nil
+	})
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts, err
+}
+
+// ResolveConflicts applies policy to every conflict between staged and target
+// by changing staged, so nothing is written to target.  Skipped files are
+// removed from staged, and a backup copies the existing file into staged
+// beside the rendered file.  The prompt policy asks about each conflict with
+// prompter, giving the diff of the conflict as the help of the question.
+func ResolveConflicts(staged billy.Filesystem, target billy.Filesystem, policy ConflictPolicy, prompter Prompter) error {
+	conflicts, err := FindConflicts(staged, target)
+	if err != nil || len(conflicts) == 0 {
+		return err
+	}
+
+	paths := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		paths[i] = c.Path
+	}
+	if policy == ConflictFail {
+		return ConflictError{Paths: paths}
+	}
+	for _, c := range conflicts {
+		action := policy
+		if policy == ConflictPrompt {
+			action, err = askConflict(c, prompter)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch action {
+		case ConflictFail:
+			return ConflictError{Paths: paths}
+		case ConflictSkip:
+			if err := staged.Remove(c.Path); err != nil {
+				return err
+			}
+		case ConflictBackup:
+			if c.Existing == nil {
+				return fmt.Errorf("cannot back up directory %s", c.Path)
+			}
+			if err := util.WriteFile(staged, backupName(staged, target, c.Path), c.Existing, 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ask what to do with c, by the path of c, with its diff as help.
+func askConflict(c Conflict, prompter Prompter) (ConflictPolicy, error) {
+	options := []string{string(ConflictOverwrite), string(ConflictSkip), string(ConflictBackup), string(ConflictFail)}
+	answer, err := prompter.Select(SelectQuestion{
+		Name:    c.Path,
+		Message: fmt.Sprintf("%s already exists", c.Path),
+		Help:    c.Diff(),
+		Options: options,
+		Validate: func(answer string) error {
+			for _, option := range options {
+				if answer == option {
+					return nil
+				}
+			}
+			return fmt.Errorf("expected one of %s", strings.Join(options, ", "))
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return ConflictPolicy(answer), nil
+}
+
+// Find a backup name for path that is used neither in staged nor target.
+func backupName(staged billy.Filesystem, target billy.Filesystem, path string) string {
+	candidate := path + backupSuffix
+	for i := 1; ; i++ {
+		_, stagedErr := staged.Stat(candidate)
+		_, targetErr := target.Stat(candidate)
+		if stagedErr != nil && targetErr != nil {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%s.%d", path, backupSuffix, i)
+	}
+}
+
+// CopyFS copies every file in src to dst.
+func CopyFS(src billy.Filesystem, dst billy.Filesystem) error {
+	return util.Walk(src, "/", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		if rel == "" {
+			return nil
+		}
+		if info.IsDir() {
+			return dst.MkdirAll(rel, info.Mode().Perm()|0700)
+		}
+		content, err := util.ReadFile(src, rel)
+		if err != nil {
+			return err
+		}
+		return util.WriteFile(dst, rel, content, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,114 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// contents reads the named files of fs, omitting files that do not exist
+func contents(fs billy.Filesystem, names ...string) map[string]string {
+	files := map[string]string{}
+	for _, name := range names {
+		if data, err := util.ReadFile(fs, name); err == nil {
+			files[name] = string(data)
+		}
+	}
+	return files
+}
+
+func testConflict(t *testing.T, when spec.G, it spec.S) {
+	var (
+		staged billy.Filesystem
+		target billy.Filesystem
+	)
+
+	it.Before(func() {
+		staged = memfs.New()
+		target = memfs.New()
+		util.WriteFile(staged, "same.txt", []byte("same"), 0600)
+		util.WriteFile(staged, "new.txt", []byte("new"), 0600)
+		util.WriteFile(staged, "a/changed.txt", []byte("generated"), 0600)
+		util.WriteFile(staged, "b.txt", []byte("generated"), 0600)
+		util.WriteFile(target, "same.txt", []byte("same"), 0600)
+		util.WriteFile(target, "a/changed.txt", []byte("mine"), 0600)
+		util.WriteFile(target, "b.txt", []byte("mine"), 0600)
+	})
+
+	when("finding conflicts", func() {
+		it("lists files with different content", func() {
+			conflicts, err := internal.FindConflicts(staged, target)
+			require.Nil(t, err)
+			require.Len(t, conflicts, 2)
+			require.Equal(t, "a/changed.txt", conflicts[0].Path)
+			require.Equal(t, "b.txt", conflicts[1].Path)
+			require.Contains(t, conflicts[1].Diff(), "-mine\n")
+		})
+
+		it("does not diff binary files", func() {
+			util.WriteFile(staged, "image.png", []byte("generated\x00"), 0600)
+			util.WriteFile(target, "image.png", []byte("mine"), 0600)
+			conflicts, err := internal.FindConflicts(staged, target)
+			require.Nil(t, err)
+			require.Len(t, conflicts, 3)
+			require.Equal(t, "image.png", conflicts[2].Path)
+			require.Equal(t, "binary files differ: image.png\n", conflicts[2].Diff())
+		})
+	})
+
+	when("resolving conflicts", func() {
+		all := []string{"same.txt", "new.txt", "a/changed.txt", "b.txt", "a/changed.txt.bak", "b.txt.bak"}
+
+		it("fails listing every conflict", func() {
+			err := internal.ResolveConflicts(staged, target, internal.ConflictFail, nil)
+			require.Equal(t, internal.ConflictError{Paths: []string{"a/changed.txt", "b.txt"}}, err)
+		})
+
+		it("skips conflicting files", func() {
+			err := internal.ResolveConflicts(staged, target, internal.ConflictSkip, nil)
+			require.Nil(t, err)
+			require.Equal(t, map[string]string{"same.txt": "same", "new.txt": "new"}, contents(staged, all...))
+		})
+
+		it("overwrites conflicting files", func() {
+			err := internal.ResolveConflicts(staged, target, internal.ConflictOverwrite, nil)
+			require.Nil(t, err)
+			require.Len(t, contents(staged, all...), 4)
+		})
+
+		it("backs up conflicting files", func() {
+			util.WriteFile(target, "b.txt.bak", []byte("older"), 0600)
+			err := internal.ResolveConflicts(staged, target, internal.ConflictBackup, nil)
+			require.Nil(t, err)
+			require.Equal(t, "mine", contents(staged, "a/changed.txt.bak")["a/changed.txt.bak"])
+			require.Equal(t, "mine", contents(staged, "b.txt.bak.1")["b.txt.bak.1"])
+			require.Equal(t, "generated", contents(staged, "b.txt")["b.txt"])
+		})
+
+		it("asks about each conflict with its diff as help", func() {
+			procedure := func(c expectConsole) {
+				c.ExpectString("a/changed.txt already exists")
+				c.Send("?")
+				c.ExpectString("+generated")
+				// \x1b\x5b\x42 is the terminal escape sequence for down arrow
+				c.SendLine("\x1b\x5b\x42")
+				c.ExpectString("b.txt already exists")
+				c.SendLine("")
+				c.ExpectEOF()
+			}
+			test := func(stdio terminal.Stdio) (map[string]string, error) {
+				err := internal.ResolveConflicts(staged, target, internal.ConflictPrompt, internal.NewSurveyPrompter(survey.WithStdio(stdio.In, stdio.Out, stdio.Err)))
+				return contents(staged, "a/changed.txt", "b.txt"), err
+			}
+			RunTest(t, procedure, test, map[string]string{"b.txt": "generated"})
+		})
+	})
+}
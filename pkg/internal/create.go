@@ -2,67 +2,143 @@ package internal
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
 
+	"github.com/go-git/go-billy/v5"
 	git "github.com/go-git/go-git/v5"
-	cp "github.com/otiai10/copy"
 	"github.com/pkg/errors"
 )
 
-// Present a local directory or a git repo as a Filesystem
-func URLToFs(url string, subPath string, tmpDir string) (string, error) {
+// Source locates a project template.  The URL is either a local directory, a
+// tar or zip archive, or a git repository, which is checked out at Ref when it
+// is set.  Git repositories are fetched through Cache, when it is not nil,
+// using the credentials resolved by Auth.  An archive must match Checksum when
+// it is set.  A local git repository is used in place without a Ref, unless
+// Checkout is set to check out its HEAD so that its commit is known.
+type Source struct {
+	URL      string
+	Ref      string
+	SubPath  string
+	Checksum string
+	Cache    *Cache
+	Auth     Auth
+	Checkout bool
+}
+
+// Present a local directory, an archive or a git repo as a directory.  Local
+// directories and cached git repos are used in place, other templates are
+// written to tmpDir.  The resolved commit SHA is returned for every git
+// repository that is checked out.  The returned func must be called once the
+// directory is no longer used, so that a cached checkout may be pruned.
+func URLToFs(source Source, tmpDir string) (string, string, func(), error) {
+	commit := ""
+	release := func() {}
+	info, statErr := os.Stat(source.URL)
+	isArchive := IsArchive(source.URL) && (isHTTPURL(source.URL) || (statErr == nil && !info.IsDir()))
+	if isArchive && source.Ref != "" {
+		return "", "", nil, fmt.Errorf("a ref cannot be used with archive %s", source.URL)
+	}
+	if !isArchive && source.Checksum != "" {
+		return "", "", nil, fmt.Errorf("a checksum can only be used with an archive")
+	}
+
+	root := tmpDir
+	isCheckout := statErr == nil && source.Ref == "" && source.Checkout && isGitRepository(source.URL)
 	// if the URL is a local folder, then do not git clone it
-	if _, err := os.Stat(url); err == nil {
-		cp.Copy(url, tmpDir)
+	if isArchive {
+		if err := FetchArchive(source.URL, source.Checksum, tmpDir); err != nil {
+			return "", "", nil, err
+		}
+	} else if statErr == nil && source.Ref == "" && !isCheckout {
+		root = source.URL
+	} else if isCheckout {
+		// the HEAD of a local repository moves, so it is not cached
+		var err error
+		commit, err = Clone(source.URL, "", source.Auth, tmpDir)
+		if err != nil {
+			return "", "", nil, err
+		}
+	} else if source.Cache != nil {
+		var err error
+		root, commit, release, err = source.Cache.Fetch(source.URL, source.Ref, source.Auth)
+		if err != nil {
+			return "", "", nil, err
+		}
 	} else {
-		_, err := git.PlainClone(tmpDir, false, &git.CloneOptions{
-			URL:   url,
-			Depth: 1,
-		})
+		var err error
+		commit, err = Clone(source.URL, source.Ref, source.Auth, tmpDir)
 		if err != nil {
-			return "", err
+			return "", "", nil, err
 		}
 	}
 
-	requestedSubPath := path.Join(tmpDir, subPath)
+	requestedSubPath := filepath.Join(root, source.SubPath)
 	if _, err := os.Stat(requestedSubPath); err != nil {
-		return "", fmt.Errorf("reequested subPath of template does not exist: %s", subPath)
+		release()
+		return "", "", nil, fmt.Errorf("requested subPath of template does not exist: %s", source.SubPath)
 	}
-	return requestedSubPath, nil
+	return requestedSubPath, commit, release, nil
 }
 
-// Create a new source project in targetDir
-func Create(inputDir string, arguments map[string]string, targetDir string) error {
-	promptFile := filepath.Join(inputDir, PromptFile)
+// Input answers the prompts of a template.  Arguments answer prompts, and
+// are available to the template when they answer none, while Defaults
+// replace the defaults of prompts and Fixed values answer prompts unless an
+// argument does, see Template.Preset.  Unless Interactive, the defaults of
+// the prompts are used in place of asking the user with the Prompter, which
+// asks on the terminal when it is nil.
+type Input struct {
+	Arguments   map[string]string
+	Defaults    map[string]string
+	Fixed       map[string]string
+	Interactive bool
+	Prompter    Prompter
+}
+
+// Create a new source project in outputFS from the template in inputFS,
+// returning the value of every variable.
+func Create(inputFS fs.FS, input Input, outputFS billy.Filesystem) (map[string]interface{}, error) {
 	var template Template
 
-	if _, ok := os.Stat(promptFile); ok == nil {
-		p, err := os.Open(promptFile)
-		if err != nil {
-			return err
-		}
-		template, err = NewTemplate(p, arguments)
+	if p, err := inputFS.Open(PromptFile); err == nil {
+		defer p.Close()
+		template, err = NewTemplate(p, input.Arguments)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		var err error
-		template, err = NewTemplate(nil, arguments)
+		template, err = NewTemplate(nil, input.Arguments)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
+	template = template.Preset(input.Defaults, input.Fixed)
 
-	values, err := template.Ask()
+	var values map[string]interface{}
+	var err error
+	if input.Interactive && input.Prompter != nil {
+		values, err = template.AskWith(input.Prompter)
+	} else if input.Interactive {
+		values, err = template.Ask()
+	} else {
+		values, err = template.Defaults()
+	}
 	if err != nil {
-		return errors.Wrap(err, "failed to prompt for values")
+		return nil, errors.Wrap(err, "failed to prompt for values")
 	}
-	err = Apply(inputDir, values, targetDir)
+	err = Apply(inputFS, values, outputFS)
 	if err != nil {
-		return errors.Wrap(err, "failed to scaffold new project")
+		return nil, errors.Wrap(err, "failed to scaffold new project")
 	}
 
-	return nil
+	return values, nil
+}
+
+// Report whether dir is the root of a git repository, either a working tree
+// or a bare repository.
+func isGitRepository(dir string) bool {
+	_, err := git.PlainOpen(dir)
+	return err == nil
 }
@@ -1,10 +1,12 @@
 package internal_test
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/sclevine/spec"
 	"github.com/stretchr/testify/require"
 
@@ -14,44 +16,36 @@ import (
 func testCreate(t *testing.T, when spec.G, it spec.S) {
 	when("provided with valid input", func() {
 		var (
-			inputDir  string
-			targetDir string
+			inputFS  fstest.MapFS
+			outputFS billy.Filesystem
 		)
 
 		it.Before(func() {
-			inputDir, _ = os.MkdirTemp("", "test")
-			targetDir, _ = os.MkdirTemp("", "test")
-			file, err := os.Create(filepath.Join(inputDir, "test.md"))
-			require.Nil(t, err)
-			_, err = file.WriteString("{{.Test}}")
-			require.Nil(t, err)
-		})
-
-		it.After(func() {
-			os.RemoveAll(inputDir)
-			os.RemoveAll(targetDir)
+			inputFS = fstest.MapFS{
+				"test.md": &fstest.MapFile{Data: []byte("{{.Test}}")},
+			}
+			outputFS = memfs.New()
 		})
 
 		it("creates valid output", func() {
-			err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
+			_, err := internal.Create(inputFS, internal.Input{Arguments: map[string]string{"Test": "quack"}, Interactive: true}, outputFS)
 			require.Nil(t, err)
 
-			buf, err := os.ReadFile(filepath.Join(targetDir, "test.md"))
+			buf, err := util.ReadFile(outputFS, "test.md")
 			require.Nil(t, err)
 			require.Equal(t, string(buf), "quack")
 		})
 
 		when("a prompt.toml file is present", func() {
 			it.Before(func() {
-				_, err := os.Create(filepath.Join(inputDir, "prompts.toml"))
-				require.Nil(t, err)
+				inputFS["prompts.toml"] = &fstest.MapFile{Data: []byte{}}
 			})
 
 			it("reads prompt.toml and creates valid output", func() {
-				err := internal.Create(inputDir, map[string]string{"Test": "quack"}, targetDir)
+				_, err := internal.Create(inputFS, internal.Input{Arguments: map[string]string{"Test": "quack"}, Interactive: true}, outputFS)
 				require.Nil(t, err)
 
-				buf, err := os.ReadFile(filepath.Join(targetDir, "test.md"))
+				buf, err := util.ReadFile(outputFS, "test.md")
 				require.Nil(t, err)
 				require.Equal(t, string(buf), "quack")
 			})
@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testDefaults(t *testing.T, when spec.G, it spec.S) {
+	template := func(prompts ...internal.Prompt) internal.TemplateImpl {
+		return internal.TemplateImpl{TPrompts: internal.Prompts{Prompts: prompts}, TArguments: map[string]string{}}
+	}
+
+	it("takes the default of each prompt", func() {
+		port := internal.Prompt{Name: "Port", Prompt: "Port", Type: internal.TypeInt, Default: int64(8080)}
+		docker := internal.Prompt{Name: "UseDocker", Prompt: "Add a Dockerfile", Type: internal.TypeBool}
+		features := internal.Prompt{Name: "Features", Prompt: "Features", Type: internal.TypeMultiSelect, Choices: internal.NewChoices("logging", "metrics")}
+		tool := internal.Prompt{Name: "BuildTool", Prompt: "Build tool", Choices: internal.NewChoices("maven", "gradle")}
+		name := internal.Prompt{Name: "Name", Prompt: "Project name", Default: "{{ .BuildTool }}-app"}
+		question := internal.NewQuestion(name)
+		tmpl := template(port, docker, features, tool, name)
+		tmpl.TQuestions = append(tmpl.TQuestions, &question)
+		for _, p := range []internal.Prompt{port, docker, features, tool} {
+			q := internal.NewQuestion(p)
+			tmpl.TQuestions = append(tmpl.TQuestions, &q)
+		}
+
+		answers, err := tmpl.Defaults()
+		require.Nil(t, err)
+		require.Equal(t, map[string]interface{}{
+			"Port":      8080,
+			"UseDocker": false,
+			"Features":  []string{},
+			"BuildTool": "maven",
+			"Name":      "maven-app",
+		}, answers)
+	})
+
+	it("lists every prompt without a valid default", func() {
+		prompts := []internal.Prompt{
+			{Name: "Name", Prompt: "Project name", Required: true},
+			{Name: "Port", Prompt: "Port to listen on", Type: internal.TypeInt},
+			{Name: "BuildTool", Prompt: "Build tool", Required: true, Choices: internal.NewChoices("maven", "gradle")},
+			{Name: "Version", Prompt: "Version", Default: "1", Validate: []internal.Rule{{Pattern: `^\d+\.\d+$`}}},
+			{Name: "Optional", Prompt: "Optional value"},
+		}
+		tmpl := template(prompts...)
+		for _, p := range prompts {
+			q := internal.NewQuestion(p)
+			tmpl.TQuestions = append(tmpl.TQuestions, &q)
+		}
+
+		_, err := tmpl.Defaults()
+		require.EqualError(t, err, `missing values in non-interactive mode:
+	Name (Project name)
+	Port (Port to listen on)
+	BuildTool (Build tool)
+	Version (Version): Version must match ^\d+\.\d+$`)
+	})
+
+	it("reads defaults from the environment", func() {
+		t.Setenv("TEST_SCAFALL_ORG", "from-env")
+		template, err := internal.NewTemplate(readCloser(`
+[[prompt]]
+name = "Org"
+prompt = "Organisation"
+default = "none"
+env = "TEST_SCAFALL_ORG"
+
+[[prompt]]
+name = "License"
+prompt = "License"
+env = "TEST_SCAFALL_UNSET"
+`), nil)
+		require.Nil(t, err)
+
+		answers, err := template.Preset(map[string]string{"Org": "config", "License": "MIT"}, nil).Defaults()
+		require.Nil(t, err)
+		require.Equal(t, map[string]interface{}{"Org": "from-env", "License": "MIT"}, answers)
+	})
+}
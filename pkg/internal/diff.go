@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// UnifiedDiff describes the changes from oldContent to newContent in unified
+// diff format.  An empty string is returned when the contents are equal.
+func UnifiedDiff(oldName string, newName string, oldContent string, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(oldContent, newContent)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	lines := []diffLine{}
+	for _, d := range diffs {
+		for _, text := range splitLines(d.Text) {
+			lines = append(lines, diffLine{op: d.Type, text: text})
+		}
+	}
+
+	out := strings.Builder{}
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range hunks(lines) {
+		writeHunk(&out, lines, hunk[0], hunk[1])
+	}
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Group changed lines, with their surrounding context, into [start, end)
+// ranges of lines.
+func hunks(lines []diffLine) [][2]int {
+	ranges := [][2]int{}
+	for i, l := range lines {
+		if l.op == diffmatchpatch.DiffEqual {
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if n := len(ranges); n > 0 && start <= ranges[n-1][1] {
+			ranges[n-1][1] = end
+		} else {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	return ranges
+}
+
+func writeHunk(out *strings.Builder, lines []diffLine, start int, end int) {
+	oldStart, newStart := 1, 1
+	for _, l := range lines[:start] {
+		if l.op != diffmatchpatch.DiffInsert {
+			oldStart++
+		}
+		if l.op != diffmatchpatch.DiffDelete {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, l := range lines[start:end] {
+		if l.op != diffmatchpatch.DiffInsert {
+			oldCount++
+		}
+		if l.op != diffmatchpatch.DiffDelete {
+			newCount++
+		}
+	}
+	// an empty range starts at the line before it
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, l := range lines[start:end] {
+		prefix := " "
+		switch l.op {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		out.WriteString(prefix + l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
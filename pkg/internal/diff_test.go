@@ -0,0 +1,35 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testUnifiedDiff(t *testing.T, when spec.G, it spec.S) {
+	when("comparing file contents", func() {
+		it("is empty for equal content", func() {
+			require.Equal(t, "", internal.UnifiedDiff("a", "b", "same\n", "same\n"))
+		})
+
+		it("shows changed lines with context", func() {
+			old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+			updated := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+			expected := "--- a/file\n+++ b/file\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"
+			require.Equal(t, expected, internal.UnifiedDiff("a/file", "b/file", old, updated))
+		})
+
+		it("shows a new file", func() {
+			expected := "--- /dev/null\n+++ b/file\n@@ -0,0 +1,2 @@\n+one\n+two\n"
+			require.Equal(t, expected, internal.UnifiedDiff("/dev/null", "b/file", "", "one\ntwo\n"))
+		})
+
+		it("marks a missing newline at the end of a file", func() {
+			expected := "--- a/file\n+++ b/file\n@@ -1,1 +1,1 @@\n-one\n+two\n\\ No newline at end of file\n"
+			require.Equal(t, expected, internal.UnifiedDiff("a/file", "b/file", "one\n", "two"))
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/pkg/errors"
+)
+
+// SplitRef separates a url@ref shorthand into its url and ref.  The userinfo
+// of a URL (https://user@host/repo) and scp-like git URLs (git@host:repo) are
+// not mistaken for a ref.
+func SplitRef(url string) (string, string) {
+	if _, err := os.Stat(url); err == nil {
+		return url, ""
+	}
+
+	i := strings.LastIndex(url, "@")
+	if i <= 0 {
+		return url, ""
+	}
+	base, ref := url[:i], url[i+1:]
+	if ref == "" || strings.Contains(ref, ":") {
+		return url, ""
+	}
+	if scheme := strings.Index(base, "://"); scheme >= 0 && !strings.Contains(base[scheme+3:], "/") {
+		return url, ""
+	}
+	return base, ref
+}
+
+// Clone a git repository into dir, checking out ref.  The ref may be a branch,
+// a tag or a full or abbreviated commit SHA.  An empty ref checks out the
+// default branch.  Credentials are taken from auth when it is not nil.  The
+// SHA of the checked out commit is returned.
+func Clone(url string, ref string, auth Auth, dir string) (string, error) {
+	method, err := authMethod(auth, url)
+	if err != nil {
+		return "", err
+	}
+	options := git.CloneOptions{
+		URL:   url,
+		Auth:  method,
+		Depth: 1,
+	}
+
+	isCommit := false
+	if ref != "" {
+		refName, err := remoteReference(url, ref, method)
+		if err != nil {
+			return "", err
+		}
+		if refName == "" {
+			// not a branch or tag, fetch full history to find the commit
+			isCommit = true
+			options.Depth = 0
+		} else {
+			options.ReferenceName = refName
+			options.SingleBranch = true
+		}
+	}
+
+	repo, err := git.PlainClone(dir, false, &options)
+	if err != nil {
+		return "", err
+	}
+
+	if isCommit {
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve ref %s in %s", ref, url)
+		}
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return "", err
+		}
+		err = worktree.Checkout(&git.CheckoutOptions{Hash: *hash})
+		if err != nil {
+			return "", errors.Wrap(err, fmt.Sprintf("failed to checkout %s", ref))
+		}
+		return hash.String(), nil
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// Find the branch or tag named ref on the remote.  An empty reference name is
+// returned when ref is neither a branch nor a tag.
+func remoteReference(url string, ref string, method transport.AuthMethod) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&git.ListOptions{Auth: method})
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("failed to list references of %s", url))
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(ref),
+		plumbing.NewTagReferenceName(ref),
+		plumbing.ReferenceName(ref),
+	}
+	for _, candidate := range candidates {
+		for _, r := range refs {
+			if r.Name() == candidate && (r.Name().IsBranch() || r.Name().IsTag()) {
+				return candidate, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func authMethod(auth Auth, url string) (transport.AuthMethod, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	method, err := auth.Method(url)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to resolve credentials for %s", url))
+	}
+	return method, nil
+}
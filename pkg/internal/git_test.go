@@ -0,0 +1,119 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// commit content to template.txt in repo, returning the commit SHA
+func commitFile(t *testing.T, repo *git.Repository, dir string, content string) string {
+	t.Helper()
+	err := os.WriteFile(filepath.Join(dir, "template.txt"), []byte(content), 0600)
+	require.Nil(t, err)
+	worktree, err := repo.Worktree()
+	require.Nil(t, err)
+	_, err = worktree.Add("template.txt")
+	require.Nil(t, err)
+	hash, err := worktree.Commit(content, &git.CommitOptions{
+		Author: &object.Signature{Name: "scafall", Email: "scafall@example.com", When: time.Now()},
+	})
+	require.Nil(t, err)
+	return hash.String()
+}
+
+func testClone(t *testing.T, when spec.G, it spec.S) {
+	when("cloning a template repository", func() {
+		var (
+			repoDir string
+			first   string
+			second  string
+			feature string
+		)
+
+		it.Before(func() {
+			repoDir = t.TempDir()
+			repo, err := git.PlainInit(repoDir, false)
+			require.Nil(t, err)
+
+			first = commitFile(t, repo, repoDir, "v1")
+			_, err = repo.CreateTag("v1.0.0", plumbing.NewHash(first), nil)
+			require.Nil(t, err)
+			second = commitFile(t, repo, repoDir, "v2")
+
+			worktree, err := repo.Worktree()
+			require.Nil(t, err)
+			err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature/x"), Create: true})
+			require.Nil(t, err)
+			feature = commitFile(t, repo, repoDir, "feature")
+			err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master})
+			require.Nil(t, err)
+		})
+
+		type TestCase struct {
+			title    string
+			ref      func() string
+			content  string
+			expected func() string
+		}
+		testCases := []TestCase{
+			{"default branch", func() string { return "" }, "v2", func() string { return second }},
+			{"branch", func() string { return "feature/x" }, "feature", func() string { return feature }},
+			{"tag", func() string { return "v1.0.0" }, "v1", func() string { return first }},
+			{"commit", func() string { return first }, "v1", func() string { return first }},
+			{"abbreviated commit", func() string { return first[:7] }, "v1", func() string { return first }},
+		}
+		for _, testCase := range testCases {
+			testCase := testCase
+			it("checks out the "+testCase.title, func() {
+				outputDir := t.TempDir()
+				commit, err := internal.Clone(repoDir, testCase.ref(), nil, outputDir)
+				require.Nil(t, err)
+				require.Equal(t, testCase.expected(), commit)
+
+				content, err := os.ReadFile(filepath.Join(outputDir, "template.txt"))
+				require.Nil(t, err)
+				require.Equal(t, testCase.content, string(content))
+			})
+		}
+
+		it("fails on an unknown ref", func() {
+			_, err := internal.Clone(repoDir, "unknown", nil, t.TempDir())
+			require.NotNil(t, err)
+		})
+	})
+}
+
+func testSplitRef(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		input string
+		url   string
+		ref   string
+	}
+	testCases := []TestCase{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", ""},
+		{"https://github.com/org/repo.git@v1.0.0", "https://github.com/org/repo.git", "v1.0.0"},
+		{"https://user@github.com/org/repo.git", "https://user@github.com/org/repo.git", ""},
+		{"https://user@github.com/org/repo.git@feature/x", "https://user@github.com/org/repo.git", "feature/x"},
+		{"git@github.com:org/repo.git", "git@github.com:org/repo.git", ""},
+		{"git@github.com:org/repo.git@abc1234", "git@github.com:org/repo.git", "abc1234"},
+		{"./template@main", "./template", "main"},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("splits "+testCase.input, func() {
+			url, ref := internal.SplitRef(testCase.input)
+			require.Equal(t, testCase.url, url)
+			require.Equal(t, testCase.ref, ref)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testHelpText(t *testing.T, when spec.G, it spec.S) {
+	when("describing a prompt", func() {
+		it("prefers help to the description", func() {
+			prompt := internal.Prompt{Description: "Path of the module", Help: "Used in go.mod", Examples: []string{"a/b", "c/d"}}
+			require.Equal(t, "Used in go.mod\nExamples: a/b, c/d", internal.HelpText(prompt))
+			prompt.Help = ""
+			require.Equal(t, "Path of the module\nExamples: a/b, c/d", internal.HelpText(prompt))
+			require.Equal(t, "", internal.HelpText(internal.Prompt{}))
+		})
+
+		it("shows help when asked", func() {
+			prompt := internal.Prompt{Name: "Module", Prompt: "Module path", Help: "Used in go.mod", Examples: []string{"a/b"}}
+			question := internal.NewQuestion(prompt)
+			template := internal.TemplateImpl{
+				TPrompts:   internal.Prompts{Prompts: []internal.Prompt{prompt}},
+				TQuestions: []*survey.Question{&question},
+			}
+			procedure := func(c expectConsole) {
+				c.ExpectString("Module path")
+				c.SendLine("?")
+				c.ExpectString("Examples: a/b")
+				c.SendLine("a/b")
+				c.ExpectEOF()
+			}
+			test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+				return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, procedure, test, map[string]interface{}{"Module": "a/b"})
+		})
+	})
+}
@@ -11,14 +11,49 @@ func TestIternal(t *testing.T) {
 	// template
 	spec.Run(t, "ReadPrompt", testReadPrompt, spec.Report(report.Terminal{}))
 	spec.Run(t, "AskPrompts", testAskPrompts, spec.Report(report.Terminal{}))
+	spec.Run(t, "AskTypedPrompts", testAskTypedPrompts, spec.Report(report.Terminal{}))
+	spec.Run(t, "ParseValue", testParseValue, spec.Report(report.Terminal{}))
+	spec.Run(t, "IsAsked", testIsAsked, spec.Report(report.Terminal{}))
+	spec.Run(t, "AskConditionalPrompts", testAskConditionalPrompts, spec.Report(report.Terminal{}))
+	spec.Run(t, "Validate", testValidate, spec.Report(report.Terminal{}))
+	spec.Run(t, "ResolvePrompt", testResolvePrompt, spec.Report(report.Terminal{}))
+	spec.Run(t, "Variables", testVariables, spec.Report(report.Terminal{}))
+	spec.Run(t, "HelpText", testHelpText, spec.Report(report.Terminal{}))
+	spec.Run(t, "Choices", testChoices, spec.Report(report.Terminal{}))
+	spec.Run(t, "CheckArguments", testCheckArguments, spec.Report(report.Terminal{}))
+	spec.Run(t, "ReadAnswers", testReadAnswers, spec.Report(report.Terminal{}))
+	spec.Run(t, "Defaults", testDefaults, spec.Report(report.Terminal{}))
+	spec.Run(t, "Config", testConfig, spec.Report(report.Terminal{}))
+	spec.Run(t, "Review", testReview, spec.Report(report.Terminal{}))
+	spec.Run(t, "Prompter", testPrompter, spec.Report(report.Terminal{}))
 	spec.Run(t, "NoArgument", testApplyNoArgument, spec.Report(report.Terminal{}))
 	// source
 	spec.Run(t, "Replace", testReplace, spec.Report(report.Terminal{}))
 	spec.Run(t, "Transform", testTransform, spec.Report(report.Terminal{}))
 	// transform
 	spec.Run(t, "Apply", testApply, spec.Report(report.Terminal{}))
+	// conflict
+	spec.Run(t, "Conflict", testConflict, spec.Report(report.Terminal{}))
+	spec.Run(t, "UnifiedDiff", testUnifiedDiff, spec.Report(report.Terminal{}))
+	// plan
+	spec.Run(t, "Plan", testPlan, spec.Report(report.Terminal{}))
+	// update
+	spec.Run(t, "Merge3", testMerge3, spec.Report(report.Terminal{}))
+	spec.Run(t, "Record", testRecord, spec.Report(report.Terminal{}))
+	spec.Run(t, "MergeProject", testMergeProject, spec.Report(report.Terminal{}))
+	// transaction
+	spec.Run(t, "Transaction", testTransaction, spec.Report(report.Terminal{}))
 	// create
 	spec.Run(t, "Create", testCreate, spec.Report(report.Terminal{}))
 	// collection
 	spec.Run(t, "Collection", testCollection, spec.Report(report.Terminal{}))
+	// git
+	spec.Run(t, "Clone", testClone, spec.Report(report.Terminal{}))
+	spec.Run(t, "SplitRef", testSplitRef, spec.Report(report.Terminal{}))
+	// archive
+	spec.Run(t, "Archive", testArchive, spec.Report(report.Terminal{}))
+	// auth
+	spec.Run(t, "Auth", testAuth, spec.Report(report.Terminal{}))
+	// cache
+	spec.Run(t, "Cache", testCache, spec.Report(report.Terminal{}))
 }
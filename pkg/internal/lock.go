@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// errLocked is returned when a lock is held by another process and not waited
+// for.
+var errLocked = errors.New("locked by another process")
+
+// A fileLock is an advisory lock on a file, shared by processes.
+type fileLock struct {
+	path string
+	f    *os.File
+}
+
+// Lock the lock file at path, creating it when it is missing.  A shared lock
+// may be held by many processes at once, an exclusive lock by one process
+// only.  Unless wait is set errLocked is returned when another process holds
+// the lock.  A lock file that is removed while waiting is created again, so
+// the lock is always held on the file at path.
+func lock(path string, exclusive bool, wait bool) (*fileLock, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(f, exclusive, wait); err != nil {
+			f.Close()
+			return nil, err
+		}
+		info, err := f.Stat()
+		current, statErr := os.Stat(path)
+		if err == nil && statErr == nil && os.SameFile(info, current) {
+			return &fileLock{path: path, f: f}, nil
+		}
+		unlockFile(f)
+		f.Close()
+	}
+}
+
+// Unlock releases the lock.
+func (l *fileLock) Unlock() {
+	unlockFile(l.f)
+	l.f.Close()
+}
+
+// Remove removes the lock file and releases the lock, which must be exclusive.
+func (l *fileLock) Remove() {
+	os.Remove(l.path)
+	l.Unlock()
+}
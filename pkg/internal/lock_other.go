@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package internal
+
+import (
+	"os"
+)
+
+// Files cannot be locked, so processes should not share a Cache.
+func lockFile(f *os.File, exclusive bool, wait bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
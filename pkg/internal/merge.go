@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// a replacement of base lines [start, end) with lines
+type edit struct {
+	start int
+	end   int
+	lines []string
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs, line by
+// line.  Where both sides change the same lines differently the result holds
+// both versions between conflict markers, labelled with oursLabel and
+// theirsLabel, and conflict is true.
+func Merge3(base string, ours string, theirs string, oursLabel string, theirsLabel string) (string, bool) {
+	if ours == theirs || base == theirs {
+		return ours, false
+	}
+	if base == ours {
+		return theirs, false
+	}
+
+	baseLines := splitLines(base)
+	oursEdits := lineEdits(base, ours)
+	theirsEdits := lineEdits(base, theirs)
+
+	out := strings.Builder{}
+	conflict := false
+	pos := 0
+	for len(oursEdits) > 0 || len(theirsEdits) > 0 {
+		// start a region at the first edit of either side, then grow it with
+		// every edit that overlaps it
+		var first edit
+		var mine, others []edit
+		if len(theirsEdits) == 0 || (len(oursEdits) > 0 && oursEdits[0].start <= theirsEdits[0].start) {
+			first, oursEdits = oursEdits[0], oursEdits[1:]
+			mine = []edit{first}
+		} else {
+			first, theirsEdits = theirsEdits[0], theirsEdits[1:]
+			others = []edit{first}
+		}
+		start, end := first.start, first.end
+		for grown := true; grown; {
+			grown = false
+			if len(oursEdits) > 0 && overlaps(oursEdits[0], start, end) {
+				mine = append(mine, oursEdits[0])
+				end = maxInt(end, oursEdits[0].end)
+				oursEdits = oursEdits[1:]
+				grown = true
+			}
+			if len(theirsEdits) > 0 && overlaps(theirsEdits[0], start, end) {
+				others = append(others, theirsEdits[0])
+				end = maxInt(end, theirsEdits[0].end)
+				theirsEdits = theirsEdits[1:]
+				grown = true
+			}
+		}
+
+		writeLines(&out, baseLines[pos:start])
+		oursRegion := applyEdits(baseLines, start, end, mine)
+		theirsRegion := applyEdits(baseLines, start, end, others)
+		switch {
+		case len(others) == 0:
+			writeLines(&out, oursRegion)
+		case len(mine) == 0 || strings.Join(oursRegion, "") == strings.Join(theirsRegion, ""):
+			writeLines(&out, theirsRegion)
+		default:
+			conflict = true
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			writeLines(&out, terminated(oursRegion))
+			out.WriteString("=======\n")
+			writeLines(&out, terminated(theirsRegion))
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+		pos = end
+	}
+	writeLines(&out, baseLines[pos:])
+	return out.String(), conflict
+}
+
+// The edits that change base into other, in order
+func lineEdits(base string, other string) []edit {
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(base, other)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	edits := []edit{}
+	var current *edit
+	i := 0
+	for _, d := range diffs {
+		lines := splitLines(d.Text)
+		if d.Type == diffmatchpatch.DiffEqual {
+			if current != nil {
+				edits = append(edits, *current)
+				current = nil
+			}
+			i += len(lines)
+			continue
+		}
+		if current == nil {
+			current = &edit{start: i, end: i}
+		}
+		if d.Type == diffmatchpatch.DiffDelete {
+			current.end += len(lines)
+			i += len(lines)
+		} else {
+			current.lines = append(current.lines, lines...)
+		}
+	}
+	if current != nil {
+		edits = append(edits, *current)
+	}
+	return edits
+}
+
+// An edit overlaps a region when it changes lines of the region, or inserts at
+// the same position
+func overlaps(e edit, start int, end int) bool {
+	return e.start < end || e.start == start
+}
+
+func applyEdits(base []string, start int, end int, edits []edit) []string {
+	result := []string{}
+	pos := start
+	for _, e := range edits {
+		result = append(result, base[pos:e.start]...)
+		result = append(result, e.lines...)
+		pos = e.end
+	}
+	return append(result, base[pos:end]...)
+}
+
+// Ensure the last line ends with a newline, so a conflict marker can follow
+func terminated(lines []string) []string {
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines = append(lines[:n-1:n-1], lines[n-1]+"\n")
+	}
+	return lines
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,74 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testMerge3(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		title    string
+		base     string
+		ours     string
+		theirs   string
+		expected string
+		conflict bool
+	}
+	testCases := []TestCase{
+		{"unchanged by theirs", "a\nb\n", "a\nB\n", "a\nb\n", "a\nB\n", false},
+		{"unchanged by ours", "a\nb\n", "a\nb\n", "a\nB\n", "a\nB\n", false},
+		{"same change", "a\nb\n", "a\nB\n", "a\nB\n", "a\nB\n", false},
+		{
+			"changes to different lines",
+			"1\n2\n3\n4\n5\n",
+			"one\n2\n3\n4\n5\n",
+			"1\n2\n3\n4\nfive\n",
+			"one\n2\n3\n4\nfive\n",
+			false,
+		},
+		{
+			"insertions at different lines",
+			"1\n2\n3\n",
+			"0\n1\n2\n3\n",
+			"1\n2\n3\n4\n",
+			"0\n1\n2\n3\n4\n",
+			false,
+		},
+		{
+			"changes to the same line",
+			"1\n2\n3\n",
+			"1\nmine\n3\n",
+			"1\ntheirs\n3\n",
+			"1\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> template\n3\n",
+			true,
+		},
+		{
+			"changes to a last line without a newline",
+			"1\n2",
+			"1\nmine",
+			"1\ntheirs",
+			"1\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> template\n",
+			true,
+		},
+		{
+			"both sides adding a file",
+			"",
+			"mine\n",
+			"theirs\n",
+			"<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> template\n",
+			true,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("merges "+testCase.title, func() {
+			merged, conflict := internal.Merge3(testCase.base, testCase.ours, testCase.theirs, "local", "template")
+			require.Equal(t, testCase.expected, merged)
+			require.Equal(t, testCase.conflict, conflict)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// PlanAction is what happens to a single file of a rendered project.
+type PlanAction string
+
+const (
+	ActionCreate    PlanAction = "create"
+	ActionOverwrite PlanAction = "overwrite"
+	ActionSkip      PlanAction = "skip"
+	// a conflict that fails, or must be asked about, under the ConflictPolicy
+	ActionConflict PlanAction = "conflict"
+)
+
+// PlannedFile describes the action taken for one file.  Diff is set when an
+// existing file differs from the rendered file.
+type PlannedFile struct {
+	Path   string      `json:"path"`
+	Action PlanAction  `json:"action"`
+	Mode   os.FileMode `json:"-"`
+	Size   int64       `json:"size"`
+	Diff   string      `json:"diff,omitempty"`
+}
+
+// MarshalJSON writes the mode of the file in octal, for example "0644".
+func (f PlannedFile) MarshalJSON() ([]byte, error) {
+	type plannedFile PlannedFile
+	return json.Marshal(struct {
+		plannedFile
+		Mode string `json:"mode"`
+	}{plannedFile(f), fmt.Sprintf("%04o", f.Mode.Perm())})
+}
+
+// Plan lists every file of a rendered project, sorted by path.
+type Plan struct {
+	Files []PlannedFile `json:"files"`
+}
+
+// NewPlan compares the rendered project in staged with target, as
+// ResolveConflicts would with policy, without changing either filesystem.
+func NewPlan(staged billy.Filesystem, target billy.Filesystem, policy ConflictPolicy) (Plan, error) {
+	conflicts, err := FindConflicts(staged, target)
+	if err != nil {
+		return Plan{}, err
+	}
+	byPath := map[string]Conflict{}
+	for _, c := range conflicts {
+		byPath[c.Path] = c
+	}
+
+	plan := Plan{Files: []PlannedFile{}}
+	err = util.Walk(staged, "/", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+		file := PlannedFile{Path: rel, Action: ActionCreate, Mode: info.Mode().Perm(), Size: info.Size()}
+
+		c, isConflict := byPath[rel]
+		if !isConflict {
+			if _, err := target.Stat(rel); err == nil {
+				// identical to the existing file
+				file.Action = ActionSkip
+			}
+			plan.Files = append(plan.Files, file)
+			return nil
+		}
+
+		file.Diff = c.Diff()
+		switch policy {
+		case ConflictSkip:
+			file.Action = ActionSkip
+		case ConflictOverwrite:
+			file.Action = ActionOverwrite
+		case ConflictBackup:
+			file.Action = ActionOverwrite
+			if c.Existing != nil {
+				backup := PlannedFile{Path: backupName(staged, target, rel), Action: ActionCreate, Mode: 0600, Size: int64(len(c.Existing))}
+				plan.Files = append(plan.Files, backup)
+			}
+		default:
+			file.Action = ActionConflict
+		}
+		plan.Files = append(plan.Files, file)
+		return nil
+	})
+	sort.Slice(plan.Files, func(i, j int) bool { return plan.Files[i].Path < plan.Files[j].Path })
+	return plan, err
+}
+
+// Count the files planned for action.
+func (p Plan) Count(action PlanAction) int {
+	n := 0
+	for _, f := range p.Files {
+		if f.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// String summarises the plan, one file per line.
+func (p Plan) String() string {
+	out := strings.Builder{}
+	for _, f := range p.Files {
+		fmt.Fprintf(&out, "%-9s %s %8d %s\n", f.Action, f.Mode, f.Size, f.Path)
+	}
+	fmt.Fprintf(&out, "%d to create, %d to overwrite, %d to skip, %d conflicting\n",
+		p.Count(ActionCreate), p.Count(ActionOverwrite), p.Count(ActionSkip), p.Count(ActionConflict))
+	return out.String()
+}
@@ -0,0 +1,86 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// actions of every file in plan keyed by path
+func actions(plan internal.Plan) map[string]internal.PlanAction {
+	files := map[string]internal.PlanAction{}
+	for _, f := range plan.Files {
+		files[f.Path] = f.Action
+	}
+	return files
+}
+
+func testPlan(t *testing.T, when spec.G, it spec.S) {
+	var (
+		staged billy.Filesystem
+		target billy.Filesystem
+	)
+
+	it.Before(func() {
+		staged = memfs.New()
+		target = memfs.New()
+		util.WriteFile(staged, "same.txt", []byte("same"), 0644)
+		util.WriteFile(staged, "dir/new.txt", []byte("new"), 0755)
+		util.WriteFile(staged, "changed.txt", []byte("generated"), 0644)
+		util.WriteFile(target, "same.txt", []byte("same"), 0644)
+		util.WriteFile(target, "changed.txt", []byte("mine"), 0644)
+	})
+
+	when("planning a project", func() {
+		it("lists the action for each file", func() {
+			plan, err := internal.NewPlan(staged, target, internal.ConflictOverwrite)
+			require.Nil(t, err)
+			require.Equal(t, map[string]internal.PlanAction{
+				"changed.txt": internal.ActionOverwrite,
+				"dir/new.txt": internal.ActionCreate,
+				"same.txt":    internal.ActionSkip,
+			}, actions(plan))
+			require.Equal(t, "changed.txt", plan.Files[0].Path)
+			require.Contains(t, plan.Files[0].Diff, "-mine")
+			require.Equal(t, int64(3), plan.Files[1].Size)
+			require.Equal(t, 0755, int(plan.Files[1].Mode))
+		})
+
+		it("follows the conflict policy", func() {
+			plan, err := internal.NewPlan(staged, target, internal.ConflictFail)
+			require.Nil(t, err)
+			require.Equal(t, internal.ActionConflict, actions(plan)["changed.txt"])
+
+			plan, err = internal.NewPlan(staged, target, internal.ConflictSkip)
+			require.Nil(t, err)
+			require.Equal(t, internal.ActionSkip, actions(plan)["changed.txt"])
+
+			plan, err = internal.NewPlan(staged, target, internal.ConflictBackup)
+			require.Nil(t, err)
+			require.Equal(t, internal.ActionOverwrite, actions(plan)["changed.txt"])
+			require.Equal(t, internal.ActionCreate, actions(plan)["changed.txt.bak"])
+		})
+
+		it("does not change either filesystem", func() {
+			_, err := internal.NewPlan(staged, target, internal.ConflictBackup)
+			require.Nil(t, err)
+			require.Equal(t, map[string]string{"same.txt": "same", "changed.txt": "mine"}, contents(target, "same.txt", "changed.txt", "dir/new.txt", "changed.txt.bak"))
+			require.Len(t, contents(staged, "same.txt", "changed.txt", "dir/new.txt", "changed.txt.bak"), 3)
+		})
+
+		it("writes the plan as JSON", func() {
+			plan, err := internal.NewPlan(staged, target, internal.ConflictSkip)
+			require.Nil(t, err)
+			data, err := json.Marshal(plan.Files[1])
+			require.Nil(t, err)
+			require.JSONEq(t, `{"path": "dir/new.txt", "action": "create", "mode": "0755", "size": 3}`, string(data))
+		})
+	})
+}
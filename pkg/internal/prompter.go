@@ -0,0 +1,268 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+)
+
+// Prompter asks the user the questions of a template, which project template
+// of a collection to use and what to do with conflicting files.  The Name of
+// a question is the name of the prompt it answers.  A Prompter calls the
+// Validate function of a question, when it is set, and asks again or returns
+// its error when the answer is not valid.
+type Prompter interface {
+	Text(question TextQuestion) (string, error)
+	Select(question SelectQuestion) (string, error)
+	Confirm(question ConfirmQuestion) (bool, error)
+	MultiSelect(question MultiSelectQuestion) ([]string, error)
+}
+
+// TextQuestion asks for free text, which is not echoed when it is Secret.
+type TextQuestion struct {
+	Name     string
+	Message  string
+	Help     string
+	Default  string
+	Secret   bool
+	Validate func(answer string) error
+}
+
+// SelectQuestion asks for one of the Options, which are described by the
+// Descriptions of the same index, if any.
+type SelectQuestion struct {
+	Name         string
+	Message      string
+	Help         string
+	Options      []string
+	Descriptions []string
+	Default      string
+	Validate     func(answer string) error
+}
+
+// ConfirmQuestion asks for yes or no.
+type ConfirmQuestion struct {
+	Name    string
+	Message string
+	Help    string
+	Default bool
+}
+
+// MultiSelectQuestion asks for any of the Options, which are described by the
+// Descriptions of the same index, if any.
+type MultiSelectQuestion struct {
+	Name         string
+	Message      string
+	Help         string
+	Options      []string
+	Descriptions []string
+	Default      []string
+	Validate     func(answer []string) error
+}
+
+type surveyPrompter struct {
+	opts []survey.AskOpt
+}
+
+// NewSurveyPrompter asks questions on the terminal with survey, passing opts
+// to every question.
+func NewSurveyPrompter(opts ...survey.AskOpt) Prompter {
+	return surveyPrompter{opts: opts}
+}
+
+func (p surveyPrompter) Text(question TextQuestion) (string, error) {
+	answer := ""
+	err := survey.AskOne(surveyText(question), &answer, p.askOpts(stringValidator(question.Validate))...)
+	return answer, err
+}
+
+func (p surveyPrompter) Select(question SelectQuestion) (string, error) {
+	answer := ""
+	err := survey.AskOne(surveySelect(question), &answer, p.askOpts(stringValidator(question.Validate))...)
+	return answer, err
+}
+
+func (p surveyPrompter) Confirm(question ConfirmQuestion) (bool, error) {
+	answer := false
+	err := survey.AskOne(surveyConfirm(question), &answer, p.opts...)
+	return answer, err
+}
+
+func (p surveyPrompter) MultiSelect(question MultiSelectQuestion) ([]string, error) {
+	answer := []string{}
+	var validator survey.Validator
+	if question.Validate != nil {
+		validator = func(answer interface{}) error {
+			selected := []string{}
+			if err := core.WriteAnswer(&selected, "", answer); err != nil {
+				return err
+			}
+			return question.Validate(selected)
+		}
+	}
+	err := survey.AskOne(surveyMultiSelect(question), &answer, p.askOpts(validator)...)
+	return answer, err
+}
+
+// The options of p followed by validator, when it is not nil.
+func (p surveyPrompter) askOpts(validator survey.Validator) []survey.AskOpt {
+	opts := append([]survey.AskOpt{}, p.opts...)
+	if validator != nil {
+		opts = append(opts, survey.WithValidator(validator))
+	}
+	return opts
+}
+
+// Adapt validate to the answers of survey, which are options for a select.
+func stringValidator(validate func(string) error) survey.Validator {
+	if validate == nil {
+		return nil
+	}
+	return func(answer interface{}) error {
+		s := ""
+		if err := core.WriteAnswer(&s, "", answer); err != nil {
+			return err
+		}
+		return validate(s)
+	}
+}
+
+func surveyText(question TextQuestion) survey.Prompt {
+	if question.Secret {
+		return &survey.Password{
+			Message: question.Message,
+			Help:    question.Help,
+		}
+	}
+	return &survey.Input{
+		Message: question.Message,
+		Help:    question.Help,
+		Default: question.Default,
+	}
+}
+
+func surveySelect(question SelectQuestion) survey.Prompt {
+	sselect := survey.Select{
+		Message:     question.Message,
+		Options:     question.Options,
+		Description: surveyDescription(question.Descriptions),
+		Help:        question.Help,
+	}
+	if question.Default != "" {
+		sselect.Default = question.Default
+	}
+	return &sselect
+}
+
+func surveyConfirm(question ConfirmQuestion) survey.Prompt {
+	return &survey.Confirm{
+		Message: question.Message,
+		Help:    question.Help,
+		Default: question.Default,
+	}
+}
+
+func surveyMultiSelect(question MultiSelectQuestion) survey.Prompt {
+	multiSelect := survey.MultiSelect{
+		Message:     question.Message,
+		Options:     question.Options,
+		Description: surveyDescription(question.Descriptions),
+		Help:        question.Help,
+	}
+	if len(question.Default) > 0 {
+		multiSelect.Default = question.Default
+	}
+	return &multiSelect
+}
+
+func surveyDescription(descriptions []string) func(string, int) string {
+	if len(descriptions) == 0 {
+		return nil
+	}
+	return func(_ string, index int) string {
+		if index < len(descriptions) {
+			return descriptions[index]
+		}
+		return ""
+	}
+}
+
+// ScriptedPrompter answers each question with the answer of the same Name in
+// Answers, as a user would type it: a confirm answer is true or false and a
+// multi-select answer is a comma separated list.  A question without an
+// answer takes its default, as when the user presses enter, so that reviewing
+// the answers confirms them.  Answers that are not valid are returned as
+// errors rather than asked again.
+type ScriptedPrompter struct {
+	Answers map[string]string
+}
+
+func (p ScriptedPrompter) Text(question TextQuestion) (string, error) {
+	answer, ok := p.Answers[question.Name]
+	if !ok {
+		answer = question.Default
+	}
+	if question.Validate != nil {
+		if err := question.Validate(answer); err != nil {
+			return "", scriptedError(question.Name, answer, err)
+		}
+	}
+	return answer, nil
+}
+
+func (p ScriptedPrompter) Select(question SelectQuestion) (string, error) {
+	answer, ok := p.Answers[question.Name]
+	if !ok {
+		answer = question.Default
+		if answer == "" && len(question.Options) > 0 {
+			answer = question.Options[0]
+		}
+	}
+	if question.Validate != nil {
+		if err := question.Validate(answer); err != nil {
+			return "", scriptedError(question.Name, answer, err)
+		}
+	}
+	return answer, nil
+}
+
+func (p ScriptedPrompter) Confirm(question ConfirmQuestion) (bool, error) {
+	answer, ok := p.Answers[question.Name]
+	if !ok {
+		return question.Default, nil
+	}
+	b, err := strconv.ParseBool(answer)
+	if err != nil {
+		return false, scriptedError(question.Name, answer, fmt.Errorf("must be true or false"))
+	}
+	return b, nil
+}
+
+func (p ScriptedPrompter) MultiSelect(question MultiSelectQuestion) ([]string, error) {
+	answer, ok := p.Answers[question.Name]
+	selected := question.Default
+	if ok {
+		selected = []string{}
+		for _, item := range strings.Split(answer, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				selected = append(selected, item)
+			}
+		}
+	}
+	if selected == nil {
+		selected = []string{}
+	}
+	if question.Validate != nil {
+		if err := question.Validate(selected); err != nil {
+			return nil, scriptedError(question.Name, strings.Join(selected, ","), err)
+		}
+	}
+	return selected, nil
+}
+
+func scriptedError(name string, answer string, err error) error {
+	return fmt.Errorf("answer %q to %s is not valid: %s", answer, name, err)
+}
@@ -0,0 +1,123 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// keeps the help of each select question asked
+type helpPrompter struct {
+	internal.ScriptedPrompter
+	help map[string]string
+}
+
+func (p helpPrompter) Select(question internal.SelectQuestion) (string, error) {
+	p.help[question.Name] = question.Help
+	return p.ScriptedPrompter.Select(question)
+}
+
+func testPrompter(t *testing.T, when spec.G, it spec.S) {
+	prompts := []internal.Prompt{
+		{Name: "Name", Prompt: "Project name", Required: true},
+		{Name: "Port", Prompt: "Port", Type: internal.TypeInt, Default: "8080"},
+		{Name: "Docker", Prompt: "Use docker", Type: internal.TypeBool},
+		{Name: "Image", Prompt: "Base image", Default: "{{ .Name }}:latest", When: ".Docker"},
+		{Name: "BuildTool", Prompt: "Build tool", Choices: []internal.Choice{{Label: "Maven", Value: "maven"}, {Label: "Gradle", Value: "gradle"}}},
+		{Name: "Features", Prompt: "Features", Type: internal.TypeMultiSelect, Choices: internal.NewChoices("logging", "tracing")},
+	}
+	template := func() internal.TemplateImpl {
+		questions := []*survey.Question{}
+		for _, p := range prompts {
+			q := internal.NewQuestion(p)
+			questions = append(questions, &q)
+		}
+		return internal.TemplateImpl{TPrompts: internal.Prompts{Prompts: prompts}, TQuestions: questions, TReview: true}
+	}
+
+	when("answering from a script", func() {
+		it("answers questions by name as the type of their prompt", func() {
+			prompter := internal.ScriptedPrompter{Answers: map[string]string{
+				"Name":      "demo",
+				"Port":      "9090",
+				"Docker":    "true",
+				"BuildTool": "Gradle",
+				"Features":  "tracing",
+			}}
+			answers, err := template().AskWith(prompter)
+			require.Nil(t, err)
+			require.Equal(t, map[string]interface{}{
+				"Name":      "demo",
+				"Port":      9090,
+				"Docker":    true,
+				"Image":     "demo:latest",
+				"BuildTool": "gradle",
+				"Features":  []string{"tracing"},
+			}, answers)
+		})
+
+		it("takes the defaults of questions without an answer and confirms the review", func() {
+			prompter := internal.ScriptedPrompter{Answers: map[string]string{"Name": "demo"}}
+			answers, err := template().AskWith(prompter)
+			require.Nil(t, err)
+			require.Equal(t, map[string]interface{}{
+				"Name":      "demo",
+				"Port":      8080,
+				"Docker":    false,
+				"BuildTool": "maven",
+				"Features":  []string{},
+			}, answers)
+		})
+
+		it("reports answers that are not valid", func() {
+			_, err := template().AskWith(internal.ScriptedPrompter{})
+			require.ErrorContains(t, err, `answer "" to Name is not valid: Value is required`)
+
+			_, err = template().AskWith(internal.ScriptedPrompter{Answers: map[string]string{"Name": "demo", "Port": "http"}})
+			require.ErrorContains(t, err, `answer "http" to Port is not valid: Port must be an integer, not "http"`)
+
+			_, err = template().AskWith(internal.ScriptedPrompter{Answers: map[string]string{"Name": "demo", "BuildTool": "ant"}})
+			require.ErrorContains(t, err, `BuildTool must be one of maven, gradle, not "ant"`)
+		})
+
+		it("aborts on review", func() {
+			prompter := internal.ScriptedPrompter{Answers: map[string]string{"Name": "demo", "review": "abort"}}
+			_, err := template().AskWith(prompter)
+			require.ErrorIs(t, err, internal.ErrAborted)
+		})
+
+		it("resolves conflicts by path", func() {
+			staged, target := memfs.New(), memfs.New()
+			util.WriteFile(staged, "a.txt", []byte("generated"), 0600)
+			util.WriteFile(staged, "b.txt", []byte("generated"), 0600)
+			util.WriteFile(target, "a.txt", []byte("mine"), 0600)
+			util.WriteFile(target, "b.txt", []byte("mine"), 0600)
+			prompter := internal.ScriptedPrompter{Answers: map[string]string{"a.txt": "skip", "b.txt": "overwrite"}}
+
+			err := internal.ResolveConflicts(staged, target, internal.ConflictPrompt, prompter)
+			require.Nil(t, err)
+			require.Equal(t, map[string]string{"b.txt": "generated"}, contents(staged, "a.txt", "b.txt"))
+
+			prompter = internal.ScriptedPrompter{Answers: map[string]string{"b.txt": "prompt"}}
+			err = internal.ResolveConflicts(staged, target, internal.ConflictPrompt, prompter)
+			require.ErrorContains(t, err, `answer "prompt" to b.txt is not valid: expected one of overwrite, skip, backup, fail`)
+		})
+
+		it("gives the diff of a conflict to the prompter", func() {
+			staged, target := memfs.New(), memfs.New()
+			util.WriteFile(staged, "a.txt", []byte("generated\n"), 0600)
+			util.WriteFile(target, "a.txt", []byte("mine\n"), 0600)
+			prompter := helpPrompter{internal.ScriptedPrompter{Answers: map[string]string{"a.txt": "skip"}}, map[string]string{}}
+
+			err := internal.ResolveConflicts(staged, target, internal.ConflictPrompt, prompter)
+			require.Nil(t, err)
+			require.Contains(t, prompter.help["a.txt"], "-mine\n+generated\n")
+		})
+	})
+}
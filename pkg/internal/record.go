@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/pkg/errors"
+)
+
+// RecordFile is written into every generated project.
+var RecordFile = filepath.Join(".scafall", "answers.toml")
+
+// Record describes how a project was generated, so that it can be updated
+// from a newer revision of its template or generated again.  Template is the
+// project template chosen from a collection and Version is the version of
+// scafall that generated the project.
+type Record struct {
+	URL      string                 `toml:"url"`
+	SubPath  string                 `toml:"sub_path,omitempty"`
+	Template string                 `toml:"template,omitempty"`
+	Commit   string                 `toml:"commit,omitempty"`
+	Version  string                 `toml:"scafall_version,omitempty"`
+	Answers  map[string]interface{} `toml:"answers"`
+}
+
+// WriteRecord writes record into the project in fsys.
+func WriteRecord(fsys billy.Filesystem, record Record) error {
+	buf := bytes.Buffer{}
+	if err := toml.NewEncoder(&buf).Encode(record); err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(filepath.Dir(RecordFile), 0755); err != nil {
+		return err
+	}
+	return util.WriteFile(fsys, RecordFile, buf.Bytes(), 0644)
+}
+
+// RecordedAnswers are the answers to record for template: every answer but
+// the computed variables and the values of password prompts.
+func RecordedAnswers(template Template, answers map[string]interface{}) map[string]interface{} {
+	excluded := map[string]bool{}
+	for _, p := range template.Arguments() {
+		if p.Type == TypePassword {
+			excluded[p.Name] = true
+		}
+	}
+	for _, v := range template.Variables() {
+		excluded[v.Name] = true
+	}
+	recorded := map[string]interface{}{}
+	for name, value := range answers {
+		if !excluded[name] {
+			recorded[name] = value
+		}
+	}
+	return recorded
+}
+
+// ReadRecord reads the record of the project in fsys.
+func ReadRecord(fsys billy.Filesystem) (Record, error) {
+	data, err := util.ReadFile(fsys, RecordFile)
+	if err != nil {
+		return Record{}, errors.Wrap(err, "project was not generated by scafall")
+	}
+	record := Record{}
+	if _, err := toml.Decode(string(data), &record); err != nil {
+		return Record{}, errors.Wrap(err, fmt.Sprintf("%s does not match required format", RecordFile))
+	}
+	return record, nil
+}
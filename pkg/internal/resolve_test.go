@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testResolvePrompt(t *testing.T, when spec.G, it spec.S) {
+	prompt := internal.Prompt{
+		Name:     "ModulePath",
+		Prompt:   "Module path",
+		Default:  "{{ .Org }}/{{ .ProjectName | kebabcase }}",
+		Choices:  internal.NewChoices("{{ .Org }}", "static"),
+		Fallback: "{{ .Org }}",
+	}
+
+	when("resolving a prompt", func() {
+		it("renders templates with the answers", func() {
+			resolved, err := internal.ResolvePrompt(prompt, map[string]interface{}{"Org": "github.com/quack", "ProjectName": "My Duck"})
+			require.Nil(t, err)
+			require.Equal(t, "github.com/quack/my-duck", resolved.Default)
+			require.Equal(t, internal.NewChoices("github.com/quack", "static"), resolved.Choices)
+			require.Equal(t, "github.com/quack", resolved.Fallback)
+			require.True(t, internal.IsTemplated(prompt))
+			require.False(t, internal.IsTemplated(resolved))
+		})
+
+		it("reports templates that fail", func() {
+			_, err := internal.ResolvePrompt(prompt, map[string]interface{}{"Org": "github.com/quack"})
+			require.ErrorContains(t, err, "failed to evaluate default of prompt ModulePath")
+		})
+
+		it("leaves templates that depend on answers unevaluated", func() {
+			static := internal.StaticPrompt(internal.Prompt{Name: "Year", Default: "{{ now | date \"2006\" }}", Fallback: "{{ .Org }}"})
+			require.Regexp(t, "^[0-9]{4}$", static.Default)
+			require.Equal(t, "{{ .Org }}", static.Fallback)
+			require.Equal(t, prompt.Default, internal.StaticPrompt(prompt).Default)
+		})
+	})
+
+	when("asking a prompt with a templated default", func() {
+		it("offers the default computed from earlier answers", func() {
+			prompts := []internal.Prompt{
+				{Name: "ProjectName", Prompt: "Project name"},
+				{Name: "ArtifactId", Prompt: "Artifact id", Default: "{{ .ProjectName | kebabcase }}"},
+			}
+			questions := []*survey.Question{}
+			for _, p := range prompts {
+				q := internal.NewQuestion(p)
+				questions = append(questions, &q)
+			}
+			template := internal.TemplateImpl{TPrompts: internal.Prompts{Prompts: prompts}, TQuestions: questions}
+			procedure := func(c expectConsole) {
+				c.ExpectString("Project name")
+				c.SendLine("My Duck")
+				c.ExpectString("(my-duck)")
+				c.SendLine("")
+				c.ExpectEOF()
+			}
+			test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+				return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+			}
+			RunTest(t, procedure, test, map[string]interface{}{"ProjectName": "My Duck", "ArtifactId": "my-duck"})
+		})
+	})
+
+	when("answering a prompt with templated choices from arguments", func() {
+		prompts := `
+[[prompt]]
+name = "Org"
+prompt = "Organisation"
+
+[[prompt]]
+name = "Team"
+prompt = "Team"
+choices = ["{{ .Org }}-a", "{{ .Org }}-b"]
+`
+
+		it("accepts a choice computed from earlier answers", func() {
+			template, err := internal.NewTemplate(readCloser(prompts), map[string]string{"Org": "acme", "Team": "acme-a"})
+			require.Nil(t, err)
+			answers, err := template.Defaults()
+			require.Nil(t, err)
+			require.Equal(t, "acme-a", answers["Team"])
+
+			template, err = internal.NewTemplate(readCloser(prompts), map[string]string{"Org": "acme", "Team": "other-a"})
+			require.Nil(t, err)
+			_, err = template.Defaults()
+			require.ErrorContains(t, err, "invalid argument Team")
+		})
+	})
+}
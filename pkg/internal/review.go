@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAborted is returned when the user aborts on reviewing their answers.
+var ErrAborted = errors.New("aborted by the user")
+
+const (
+	reviewConfirm = -1
+	reviewAbort   = -2
+
+	confirmOption = "confirm and generate the project"
+	abortOption   = "abort"
+	hiddenValue   = "********"
+
+	reviewQuestion = "review"
+)
+
+// Ask the user to review the answers to the asked prompts with prompter,
+// returning reviewConfirm, reviewAbort or the index of the prompt to ask
+// again.
+func review(asked []Prompt, answers map[string]interface{}, prompter Prompter) (int, error) {
+	options := []string{confirmOption}
+	for _, prompt := range asked {
+		value := FormatValue(answers[prompt.Name])
+		if prompt.Type == TypePassword && value != "" {
+			value = hiddenValue
+		}
+		options = append(options, fmt.Sprintf("%s: %s", prompt.Name, value))
+	}
+	options = append(options, abortOption)
+
+	answer, err := prompter.Select(SelectQuestion{
+		Name:    reviewQuestion,
+		Message: "Review the answers, choose one to change it",
+		Options: options,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i, option := range options {
+		if option != answer {
+			continue
+		}
+		switch i {
+		case 0:
+			return reviewConfirm, nil
+		case len(options) - 1:
+			return reviewAbort, nil
+		default:
+			return i - 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not an answer to review", answer)
+}
@@ -0,0 +1,107 @@
+package internal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testReview(t *testing.T, when spec.G, it spec.S) {
+	const down = "\x1b\x5b\x42"
+	prompts := []internal.Prompt{
+		{Name: "BuildTool", Prompt: "Build tool", Choices: internal.NewChoices("maven", "gradle")},
+		{Name: "GradleDsl", Prompt: "Gradle DSL", Choices: internal.NewChoices("kotlin", "groovy"), When: `eq .BuildTool "gradle"`},
+		{Name: "Name", Prompt: "Project name", Default: "{{ .BuildTool }}-app"},
+	}
+	template := func() internal.TemplateImpl {
+		questions := []*survey.Question{}
+		for _, p := range prompts {
+			q := internal.NewQuestion(p)
+			questions = append(questions, &q)
+		}
+		return internal.TemplateImpl{TPrompts: internal.Prompts{Prompts: prompts}, TQuestions: questions, TReview: true}
+	}
+	ask := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+		return template().Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+	}
+
+	it("returns the answers once confirmed", func() {
+		procedure := func(c expectConsole) {
+			c.ExpectString("Build tool")
+			c.SendLine("")
+			c.ExpectString("Project name")
+			c.SendLine("")
+			c.ExpectString("Name: maven-app")
+			c.SendLine("")
+			c.ExpectEOF()
+		}
+		RunTest(t, procedure, ask, map[string]interface{}{"BuildTool": "maven", "Name": "maven-app"})
+	})
+
+	it("asks prompts that apply once an answer changes", func() {
+		procedure := func(c expectConsole) {
+			c.ExpectString("Build tool")
+			c.SendLine("")
+			c.ExpectString("Project name")
+			c.SendLine("demo")
+			c.ExpectString("Review the answers")
+			// change BuildTool
+			c.Send(down)
+			c.SendLine("")
+			c.ExpectString("Build tool")
+			c.Send(down)
+			c.SendLine("")
+			c.ExpectString("Gradle DSL")
+			c.SendLine("")
+			c.ExpectString("GradleDsl: kotlin")
+			c.SendLine("")
+			c.ExpectEOF()
+		}
+		RunTest(t, procedure, ask, map[string]interface{}{"BuildTool": "gradle", "GradleDsl": "kotlin", "Name": "demo"})
+	})
+
+	it("offers the current answer when asking again", func() {
+		procedure := func(c expectConsole) {
+			c.ExpectString("Build tool")
+			c.SendLine("")
+			c.ExpectString("Project name")
+			c.SendLine("demo")
+			c.ExpectString("Review the answers")
+			// change Name
+			c.Send(down)
+			c.Send(down)
+			c.SendLine("")
+			c.ExpectString("(demo)")
+			c.SendLine("renamed")
+			c.ExpectString("Name: renamed")
+			c.SendLine("")
+			c.ExpectEOF()
+		}
+		RunTest(t, procedure, ask, map[string]interface{}{"BuildTool": "maven", "Name": "renamed"})
+	})
+
+	it("aborts", func() {
+		procedure := func(c expectConsole) {
+			c.ExpectString("Build tool")
+			c.SendLine("")
+			c.ExpectString("Project name")
+			c.SendLine("")
+			c.ExpectString("abort")
+			c.Send(down)
+			c.Send(down)
+			c.Send(down)
+			c.SendLine("")
+			c.ExpectEOF()
+		}
+		test := func(stdio terminal.Stdio) (bool, error) {
+			_, err := ask(stdio)
+			return errors.Is(err, internal.ErrAborted), nil
+		}
+		RunTest(t, procedure, test, true)
+	})
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -9,6 +10,8 @@ import (
 	"strings"
 
 	t "github.com/coveooss/gotemplate/v3/template"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
 )
 
 type SourceFile struct {
@@ -17,32 +20,55 @@ type SourceFile struct {
 	FileMode    fs.FileMode
 }
 
-func (s SourceFile) Transform(inputDir string, outputDir string, vars map[string]string) error {
+// Transform writes the file to outputFS with vars replaced in its path and
+// content.  Files without content, such as binaries, are copied from inputFS;
+// inputFS is never modified.
+func (s SourceFile) Transform(inputFS fs.FS, outputFS billy.Filesystem, vars map[string]interface{}) error {
 	outputFile, err := s.Replace(vars)
 	if err != nil {
 		return err
 	}
 
-	dstDir := filepath.Join(outputDir, filepath.Dir(outputFile.FilePath))
-	mkdirErr := os.MkdirAll(dstDir, 0744)
+	outputPath := filepath.FromSlash(outputFile.FilePath)
+	dstDir := filepath.Dir(outputPath)
+	mkdirErr := outputFS.MkdirAll(dstDir, 0744)
 	if mkdirErr != nil {
 		return fmt.Errorf("failed to create target directory %s", dstDir)
 	}
 
-	outputPath := filepath.Join(outputDir, outputFile.FilePath)
 	if outputFile.FileContent == "" {
-		inputPath := filepath.Join(inputDir, s.FilePath)
-		mvErr := os.Rename(inputPath, outputPath)
-		if mvErr != nil {
-			return fmt.Errorf("failed to rename %s to %s", s.FilePath, outputFile.FilePath)
+		cpErr := copyFromFS(inputFS, s.FilePath, outputFS, outputPath, outputFile.FileMode|0600)
+		if cpErr != nil {
+			return fmt.Errorf("failed to copy %s to %s", s.FilePath, outputFile.FilePath)
 		}
 	} else {
-		os.WriteFile(outputPath, []byte(outputFile.FileContent), outputFile.FileMode|0600)
+		writeErr := util.WriteFile(outputFS, outputPath, []byte(outputFile.FileContent), outputFile.FileMode|0600)
+		if writeErr != nil {
+			return fmt.Errorf("failed to write %s", outputFile.FilePath)
+		}
 	}
 	return nil
 }
 
-func replaceUnknownVars(vars map[string]string, content string) string {
+func copyFromFS(inputFS fs.FS, name string, outputFS billy.Filesystem, outputPath string, mode fs.FileMode) error {
+	in, err := inputFS.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := outputFS.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func replaceUnknownVars(vars map[string]interface{}, content string) string {
 	regex := regexp.MustCompile(`{{[ \t]*\.\w+`)
 	transformed := content
 	for _, token := range regex.FindAllString(content, -1) {
@@ -56,20 +82,31 @@ func replaceUnknownVars(vars map[string]string, content string) string {
 	return transformed
 }
 
-func (s SourceFile) Replace(vars map[string]string) (SourceFile, error) {
+// Create the gotemplate engine, with sprig functions, that renders vars.
+func newRenderer(vars map[string]interface{}) (*t.Template, error) {
 	opts := t.DefaultOptions().
 		Set(t.Overwrite, t.Sprig, t.StrictErrorCheck, t.AcceptNoValue).
 		Unset(t.Razor)
-	template, err := t.NewTemplate(
+	return t.NewTemplate(
 		"",
 		vars,
 		"",
 		opts)
+}
+
+// Replace renders the path and content of s with vars.  The renderer adds its
+// own context to the map it is given, so vars is copied, as Render does.
+func (s SourceFile) Replace(vars map[string]interface{}) (SourceFile, error) {
+	context := make(map[string]interface{}, len(vars))
+	for key, value := range vars {
+		context[key] = value
+	}
+	template, err := newRenderer(context)
 	if err != nil {
 		return SourceFile{}, err
 	}
 
-	filePath := replaceUnknownVars(vars, s.FilePath)
+	filePath := replaceUnknownVars(context, s.FilePath)
 	transformedFilePath, err := template.ProcessContent(filePath, "")
 	if err != nil {
 		return SourceFile{}, err
@@ -78,7 +115,7 @@ func (s SourceFile) Replace(vars map[string]string) (SourceFile, error) {
 
 	transformedFileContent := ""
 	if s.FileContent != "" {
-		fileContent := replaceUnknownVars(vars, s.FileContent)
+		fileContent := replaceUnknownVars(context, s.FileContent)
 		transformedFileContent, err = template.ProcessContent(fileContent, "")
 		if err != nil {
 			return SourceFile{}, err
@@ -1,10 +1,12 @@
 package internal_test
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/sclevine/spec"
 	h "github.com/stretchr/testify/assert"
 
@@ -14,19 +16,19 @@ import (
 func testReplace(t *testing.T, when spec.G, it spec.S) {
 	type TestCase struct {
 		file         internal.SourceFile
-		vars         map[string]string
+		vars         map[string]interface{}
 		expectedName string
 	}
 
 	testCases := []TestCase{
 		{
 			internal.SourceFile{FilePath: "{{.Foo}}", FileContent: ""},
-			map[string]string{"Foo": "Bar"},
+			map[string]interface{}{"Foo": "Bar"},
 			"Bar",
 		},
 		{
 			internal.SourceFile{FilePath: "{{.Foo}}"},
-			map[string]string{"Bar": "Bar"},
+			map[string]interface{}{"Bar": "Bar"},
 			"{{.Foo}}",
 		},
 	}
@@ -45,20 +47,20 @@ func testReplace(t *testing.T, when spec.G, it spec.S) {
 func testTransform(t *testing.T, when spec.G, it spec.S) {
 	type TestCase struct {
 		file            internal.SourceFile
-		vars            map[string]string
+		vars            map[string]interface{}
 		expectedName    string
 		expectedContent string
 	}
 	testCases := []TestCase{
 		{
 			internal.SourceFile{FilePath: "{{.Foo}}", FileContent: "{{.Foo}}"},
-			map[string]string{"Foo": "Bar"},
+			map[string]interface{}{"Foo": "Bar"},
 			"Bar",
 			"Bar",
 		},
 		{
 			internal.SourceFile{FilePath: "{{.Foo}}"},
-			map[string]string{"Bar": "Bar"},
+			map[string]interface{}{"Bar": "Bar"},
 			"{{.Foo}}",
 			"",
 		},
@@ -67,21 +69,21 @@ func testTransform(t *testing.T, when spec.G, it spec.S) {
 		testCase := testCase
 		when("variable replacement is called", func() {
 			var (
-				inputDir  string
-				outputDir string
+				inputFS  fstest.MapFS
+				outputFS billy.Filesystem
 			)
 			it.Before(func() {
-				inputDir = t.TempDir()
-				outputDir = t.TempDir()
-				err := os.WriteFile(filepath.Join(inputDir, testCase.file.FilePath), []byte(testCase.file.FileContent), 0400)
-				h.Nil(t, err)
+				inputFS = fstest.MapFS{
+					testCase.file.FilePath: &fstest.MapFile{Data: []byte(testCase.file.FileContent), Mode: 0400},
+				}
+				outputFS = memfs.New()
 			})
 
 			it("correctly replaces tokens", func() {
-				err := testCase.file.Transform(inputDir, outputDir, testCase.vars)
+				err := testCase.file.Transform(inputFS, outputFS, testCase.vars)
 				h.Nil(t, err)
 
-				contents, err := os.ReadFile(filepath.Join(outputDir, testCase.expectedName))
+				contents, err := util.ReadFile(outputFS, testCase.expectedName)
 				h.Nil(t, err)
 				h.Equal(t, string(contents), testCase.expectedContent)
 			})
@@ -3,68 +3,195 @@ package internal
 import (
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/AlecAivazis/survey/v2/core"
 	"github.com/BurntSushi/toml"
 	"github.com/pkg/errors"
+
+	"github.com/buildpacks-community/scafall/pkg/internal/util"
 )
 
 const (
 	PromptFile string = "prompts.toml"
+
+	// rendered in place of a variable without a value
+	noValue string = "<no value>"
 )
 
+// Prompt is a question asked for the value of a variable.  Type is one of
+// PromptTypes and defaults to a string; Min and Max bound int and float
+// values.  Default is a value of the prompt's type, such as true or 8080.
+//
+// A prompt with a When expression, such as {{ eq .BuildTool "gradle" }}, is
+// only asked when the expression renders to a true value using the answers
+// to the prompts before it.  A prompt that is not asked takes the Fallback
+// value, or is left unset when there is no Fallback.  Default, Choices and
+// Fallback may also be templates of earlier answers, see ResolvePrompt.
+//
+// Choices are shown by their labels and bound by their values, see Choice.
+//
+// Description is a one line summary of the prompt, while Help and Examples
+// are shown when the user asks for help.  When the environment variable named
+// by Env is set, its value replaces the Default.
 type Prompt struct {
-	Name     string   `toml:"name" binding:"required"`
-	Prompt   string   `toml:"prompt" binding:"required"`
-	Required bool     `toml:"required"`
-	Default  string   `toml:"default"`
-	Choices  []string `toml:"choices,omitempty"`
+	Name        string      `toml:"name" json:"name" binding:"required"`
+	Prompt      string      `toml:"prompt" json:"prompt" binding:"required"`
+	Type        string      `toml:"type,omitempty" json:"type,omitempty"`
+	Required    bool        `toml:"required" json:"required"`
+	Default     interface{} `toml:"default" json:"default,omitempty"`
+	Choices     []Choice    `toml:"choices,omitempty" json:"choices,omitempty"`
+	Min         *float64    `toml:"min,omitempty" json:"min,omitempty"`
+	Max         *float64    `toml:"max,omitempty" json:"max,omitempty"`
+	When        string      `toml:"when,omitempty" json:"when,omitempty"`
+	Fallback    interface{} `toml:"fallback,omitempty" json:"fallback,omitempty"`
+	Validate    []Rule      `toml:"validate,omitempty" json:"validate,omitempty"`
+	Description string      `toml:"description,omitempty" json:"description,omitempty"`
+	Help        string      `toml:"help,omitempty" json:"help,omitempty"`
+	Examples    []string    `toml:"examples,omitempty" json:"examples,omitempty"`
+	Env         string      `toml:"env,omitempty" json:"env,omitempty"`
 }
 
 type Prompts struct {
-	Prompts []Prompt `toml:"prompt"`
+	Prompts   []Prompt   `toml:"prompt" json:"prompts"`
+	Variables []Variable `toml:"variable" json:"variables"`
 }
 
 type Template interface {
 	Arguments() []Prompt
-	Ask(...survey.AskOpt) (map[string]string, error)
+	Variables() []Variable
+	Ask(...survey.AskOpt) (map[string]interface{}, error)
+	AskWith(Prompter) (map[string]interface{}, error)
+	Defaults() (map[string]interface{}, error)
+	Preset(defaults map[string]string, fixed map[string]string) Template
 }
 
+// TemplateImpl asks the TQuestions of the prompts that are not answered by
+// TArguments.  When TReview is set the user reviews the answers before they
+// are returned, see Ask.
 type TemplateImpl struct {
 	TPrompts   Prompts
 	TQuestions []*survey.Question
 	TArguments map[string]string
+	TReview    bool
 }
 
+// NewQuestion is the survey question for prompt, see promptQuestion.
 func NewQuestion(prompt Prompt) survey.Question {
 	p := survey.Question{
 		Name: prompt.Name,
 	}
-	if len(prompt.Choices) != 0 {
-		sselect := survey.Select{
+	switch q := promptQuestion(prompt).(type) {
+	case ConfirmQuestion:
+		// false is a valid answer, so there is nothing to require
+		p.Prompt = surveyConfirm(q)
+		return p
+	case MultiSelectQuestion:
+		p.Prompt = surveyMultiSelect(q)
+	case SelectQuestion:
+		p.Prompt = surveySelect(q)
+	case TextQuestion:
+		p.Prompt = surveyText(q)
+	}
+	if validators := promptValidators(prompt); len(validators) > 0 {
+		p.Validate = survey.ComposeValidators(validators...)
+	}
+	return p
+}
+
+// The question for prompt: a ConfirmQuestion for a bool, a MultiSelectQuestion
+// for a multiselect, a SelectQuestion for a prompt with choices or else a
+// TextQuestion.  Choices are offered by their labels.
+func promptQuestion(prompt Prompt) interface{} {
+	defaultValue := FormatValue(prompt.Default)
+	help := HelpText(prompt)
+	switch {
+	case prompt.Type == TypeBool:
+		confirm := ConfirmQuestion{
+			Name:    prompt.Name,
 			Message: prompt.Prompt,
-			Options: prompt.Choices,
-			Default: prompt.Choices[0],
+			Help:    help,
+		}
+		if b, err := ParseValue(prompt, defaultValue); err == nil {
+			confirm.Default = b.(bool)
+		}
+		return confirm
+	case prompt.Type == TypeMultiSelect:
+		multiSelect := MultiSelectQuestion{
+			Name:         prompt.Name,
+			Message:      prompt.Prompt,
+			Help:         help,
+			Options:      choiceLabels(prompt.Choices),
+			Descriptions: choiceDescriptions(prompt.Choices),
 		}
-		if prompt.Default != "" {
-			sselect.Default = prompt.Default
+		if defaultValue != "" {
+			selected, _ := ParseValue(prompt, defaultValue)
+			for _, value := range selected.([]string) {
+				if label := choiceLabel(prompt.Choices, value); label != "" {
+					multiSelect.Default = append(multiSelect.Default, label)
+				}
+			}
 		}
-		p.Prompt = &sselect
-	} else {
-		input := survey.Input{
+		return multiSelect
+	case prompt.Type == TypePassword:
+		return TextQuestion{
+			Name:    prompt.Name,
 			Message: prompt.Prompt,
+			Help:    help,
+			Secret:  true,
 		}
-		if prompt.Default != "" {
-			input.Default = prompt.Default
+	case len(prompt.Choices) != 0:
+		sselect := SelectQuestion{
+			Name:         prompt.Name,
+			Message:      prompt.Prompt,
+			Help:         help,
+			Options:      choiceLabels(prompt.Choices),
+			Descriptions: choiceDescriptions(prompt.Choices),
+			Default:      prompt.Choices[0].Display(),
+		}
+		if label := choiceLabel(prompt.Choices, defaultValue); label != "" {
+			sselect.Default = label
+		}
+		return sselect
+	default:
+		return TextQuestion{
+			Name:    prompt.Name,
+			Message: prompt.Prompt,
+			Help:    help,
+			Default: defaultValue,
 		}
-		p.Prompt = &input
 	}
+}
 
+// The checks of the answer to prompt that do not depend on other answers.
+func promptValidators(prompt Prompt) []survey.Validator {
+	validators := []survey.Validator{}
 	if prompt.Required {
-		p.Validate = survey.ComposeValidators(survey.Required)
+		validators = append(validators, survey.Required)
 	}
-	return p
+	if prompt.Type == TypeInt || prompt.Type == TypeFloat {
+		validators = append(validators, func(answer interface{}) error {
+			_, err := ParseValue(prompt, FormatValue(answer))
+			return err
+		})
+	}
+	return validators
+}
+
+// HelpText is shown when the user asks for help with prompt: its Help, or its
+// Description when there is no Help, followed by any Examples.
+func HelpText(prompt Prompt) string {
+	lines := []string{}
+	if prompt.Help != "" {
+		lines = append(lines, prompt.Help)
+	} else if prompt.Description != "" {
+		lines = append(lines, prompt.Description)
+	}
+	if len(prompt.Examples) > 0 {
+		lines = append(lines, "Examples: "+strings.Join(prompt.Examples, ", "))
+	}
+	return strings.Join(lines, "\n")
 }
 
 func NewTemplate(promptFile io.ReadCloser, arguments map[string]string) (Template, error) {
@@ -83,11 +210,44 @@ func NewTemplate(promptFile io.ReadCloser, arguments map[string]string) (Templat
 		}
 	}
 
-	questions := make([]*survey.Question, 0)
+	names := map[string]bool{}
 	for _, prompt := range prompts.Prompts {
+		names[prompt.Name] = true
+	}
+	for _, v := range prompts.Variables {
+		if v.Name == "" || v.Value == "" {
+			return nil, fmt.Errorf("%s file contains variable with missing required field; name or value required", promptFile)
+		}
+		if names[v.Name] {
+			return nil, fmt.Errorf("variable %s has the same name as a prompt or variable", v.Name)
+		}
+		names[v.Name] = true
+	}
+	if _, err := OrderVariables(prompts.Variables); err != nil {
+		return nil, err
+	}
+
+	questions := make([]*survey.Question, 0)
+	for i, prompt := range prompts.Prompts {
 		if prompt.Name == "" || prompt.Prompt == "" {
 			return nil, fmt.Errorf("%s file contains prompt with missing required field; name or prompt required", promptFile)
 		}
+		if prompt.Type != "" && !util.Contains(PromptTypes, prompt.Type) {
+			return nil, fmt.Errorf("prompt %s has unknown type %s, expected one of %s", prompt.Name, prompt.Type, strings.Join(PromptTypes, ", "))
+		}
+		if prompt.Type == TypeMultiSelect && len(prompt.Choices) == 0 {
+			return nil, fmt.Errorf("multiselect prompt %s requires choices", prompt.Name)
+		}
+		if err := checkRules(prompt); err != nil {
+			return nil, err
+		}
+		if err := checkSeparator(prompt); err != nil {
+			return nil, err
+		}
+		if value, ok := envDefault(prompt); ok {
+			prompt.Default = value
+			prompts.Prompts[i] = prompt
+		}
 
 		// Remove question from survey if an argument has been provided
 		if _, ok := arguments[prompt.Name]; !ok {
@@ -100,6 +260,7 @@ func NewTemplate(promptFile io.ReadCloser, arguments map[string]string) (Templat
 		TPrompts:   prompts,
 		TQuestions: questions,
 		TArguments: arguments,
+		TReview:    true,
 	}, nil
 }
 
@@ -107,23 +268,382 @@ func (t TemplateImpl) Arguments() []Prompt {
 	return t.TPrompts.Prompts
 }
 
-func (t TemplateImpl) Ask(opts ...survey.AskOpt) (map[string]string, error) {
-	response := map[string]interface{}{}
-	if len(t.TQuestions) != 0 {
-		err := survey.Ask(t.TQuestions, &response, opts...)
+func (t TemplateImpl) Variables() []Variable {
+	return t.TPrompts.Variables
+}
+
+// Preset the prompts of the template that are not answered by an argument.
+// The defaults replace the Default of prompts, which are still asked, unless
+// the Default was read from the environment, while the fixed values answer
+// prompts as an argument would.  Values that name no prompt are ignored.
+func (t TemplateImpl) Preset(defaults map[string]string, fixed map[string]string) Template {
+	arguments := make(map[string]string, len(t.TArguments))
+	for name, value := range t.TArguments {
+		arguments[name] = value
+	}
+	prompts := make([]Prompt, len(t.TPrompts.Prompts))
+	questions := make([]*survey.Question, 0)
+	for i, prompt := range t.TPrompts.Prompts {
+		if _, ok := arguments[prompt.Name]; !ok {
+			if value, ok := fixed[prompt.Name]; ok {
+				arguments[prompt.Name] = value
+			} else {
+				// a default read from the environment is kept
+				if value, ok := defaults[prompt.Name]; ok {
+					if _, fromEnv := envDefault(prompt); !fromEnv {
+						prompt.Default = value
+					}
+				}
+				question := NewQuestion(prompt)
+				questions = append(questions, &question)
+			}
+		}
+		prompts[i] = prompt
+	}
+	return TemplateImpl{
+		TPrompts:   Prompts{Prompts: prompts, Variables: t.TPrompts.Variables},
+		TQuestions: questions,
+		TArguments: arguments,
+		TReview:    t.TReview,
+	}
+}
+
+// Ask the questions of the template one at a time, returning the value of
+// every prompt as its type and every computed variable, along with the
+// Arguments.  Arguments that answer a prompt are parsed into the type of the
+// prompt.  Prompts whose When expression is false are skipped.
+//
+// With TReview, the answers are then listed for the user to confirm, to
+// change one of them or to abort with ErrAborted.  Once an answer changes,
+// the When expressions of the prompts are evaluated again and any prompt that
+// now applies is asked.
+func (t TemplateImpl) Ask(opts ...survey.AskOpt) (map[string]interface{}, error) {
+	return t.AskWith(NewSurveyPrompter(opts...))
+}
+
+// AskWith asks the questions of the template with prompter, as Ask does.
+func (t TemplateImpl) AskWith(prompter Prompter) (map[string]interface{}, error) {
+	return t.answer(prompter)
+}
+
+// Defaults answers the questions of the template without asking them, as Ask
+// would when every default is accepted.  A select takes its first choice
+// unless it is required.  Every prompt without a valid default is listed in
+// a single error.
+func (t TemplateImpl) Defaults() (map[string]interface{}, error) {
+	return t.answer(nil)
+}
+
+// Answer the prompts, asking the user with prompter unless it is nil.
+func (t TemplateImpl) answer(prompter Prompter) (map[string]interface{}, error) {
+	questioned := map[string]bool{}
+	for _, q := range t.TQuestions {
+		questioned[q.Name] = true
+	}
+
+	given := map[string]interface{}{}
+	answers, asked, err := t.answerPrompts(questioned, given, prompter)
+	if err != nil {
+		return nil, err
+	}
+	for prompter != nil && t.TReview && len(asked) > 0 {
+		choice, err := review(asked, answers, prompter)
 		if err != nil {
 			return nil, err
 		}
+		if choice == reviewConfirm {
+			break
+		}
+		if choice == reviewAbort {
+			return nil, ErrAborted
+		}
+
+		// ask again with the current answer as the default, then ask any
+		// prompt that now applies
+		prompt := asked[choice]
+		if prompt.Type != TypePassword {
+			prompt.Default = answers[prompt.Name]
+		}
+		if given[prompt.Name], err = askPrompt(prompt, answers, prompter); err != nil {
+			return nil, err
+		}
+		if answers, asked, err = t.answerPrompts(questioned, given, prompter); err != nil {
+			return nil, err
+		}
 	}
 
-	answers := make(map[string]string, len(response))
-	for key, value := range response {
-		val := ""
-		core.WriteAnswer(&val, key, value)
-		answers[key] = val
+	if err := ComputeVariables(t.TPrompts.Variables, answers); err != nil {
+		return nil, err
 	}
+	return answers, nil
+}
+
+// Answer each prompt in turn: from the Arguments, from its Fallback when its
+// When expression is false, from the answers given by the user before, or
+// else by asking the user with prompter, or taking its default when prompter
+// is nil.  Only the prompts named in questioned are asked.  The answers are
+// returned along with the prompts that were asked.
+func (t TemplateImpl) answerPrompts(questioned map[string]bool, given map[string]interface{}, prompter Prompter) (map[string]interface{}, []Prompt, error) {
+	prompts := map[string]bool{}
+	for _, p := range t.TPrompts.Prompts {
+		prompts[p.Name] = true
+	}
+
+	answers := map[string]interface{}{}
 	for key, value := range t.TArguments {
-		answers[key] = value
+		if !prompts[key] {
+			answers[key] = value
+		}
 	}
-	return answers, nil
+	missing := []string{}
+	askedPrompts := []Prompt{}
+	for _, prompt := range t.TPrompts.Prompts {
+		asked, err := IsAsked(prompt, answers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if value, ok := t.TArguments[prompt.Name]; ok {
+			// the choices of the prompt may depend on earlier answers
+			resolved, err := ResolvePrompt(prompt, answers)
+			if err != nil {
+				return nil, nil, err
+			}
+			val, err := ParseValue(resolved, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := Validate(resolved, val, answers); err != nil {
+				return nil, nil, errors.Wrap(err, fmt.Sprintf("invalid argument %s", prompt.Name))
+			}
+			answers[prompt.Name] = val
+		} else if !asked {
+			if prompt.Fallback != nil {
+				resolved, err := ResolvePrompt(prompt, answers)
+				if err != nil {
+					return nil, nil, err
+				}
+				val, err := ParseValue(prompt, FormatValue(resolved.Fallback))
+				if err != nil {
+					return nil, nil, err
+				}
+				answers[prompt.Name] = val
+			}
+		} else if questioned[prompt.Name] {
+			if value, ok := given[prompt.Name]; ok {
+				answers[prompt.Name] = value
+			} else if prompter != nil {
+				val, err := askPrompt(prompt, answers, prompter)
+				if err != nil {
+					return nil, nil, err
+				}
+				given[prompt.Name] = val
+				answers[prompt.Name] = val
+			} else {
+				resolved, err := ResolvePrompt(prompt, answers)
+				if err != nil {
+					return nil, nil, err
+				}
+				val, err := defaultAnswer(resolved, answers)
+				if err != nil {
+					missing = append(missing, err.Error())
+					continue
+				}
+				answers[prompt.Name] = val
+			}
+			askedPrompts = append(askedPrompts, prompt)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("missing values in non-interactive mode:\n\t%s", strings.Join(missing, "\n\t"))
+	}
+	return answers, askedPrompts, nil
+}
+
+// Ask the question for prompt with prompter, rendering its templates with the
+// answers given so far, and return the answer as the type of prompt.
+func askPrompt(prompt Prompt, answers map[string]interface{}, prompter Prompter) (interface{}, error) {
+	resolved := prompt
+	if IsTemplated(prompt) {
+		var err error
+		if resolved, err = ResolvePrompt(prompt, answers); err != nil {
+			return nil, err
+		}
+	}
+	// the rules are checked against the answers given so far
+	validate := survey.ComposeValidators(append(promptValidators(resolved), Validator(resolved, answers))...)
+
+	var answer interface{}
+	var err error
+	switch q := promptQuestion(resolved).(type) {
+	case ConfirmQuestion:
+		answer, err = prompter.Confirm(q)
+	case MultiSelectQuestion:
+		q.Validate = func(selected []string) error { return validate(selected) }
+		answer, err = prompter.MultiSelect(q)
+	case SelectQuestion:
+		q.Validate = func(label string) error { return validate(label) }
+		answer, err = prompter.Select(q)
+	case TextQuestion:
+		q.Validate = func(text string) error { return validate(text) }
+		answer, err = prompter.Text(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return answerValue(resolved, answer)
+}
+
+// The answer to prompt when its default is accepted.  The error describes
+// the prompt, with its text, when it has no valid default.
+func defaultAnswer(prompt Prompt, answers map[string]interface{}) (interface{}, error) {
+	value := FormatValue(prompt.Default)
+	if value == "" && len(prompt.Choices) > 0 && prompt.Type != TypeMultiSelect && !prompt.Required {
+		value = prompt.Choices[0].Value
+	}
+	if value == "" && (prompt.Required || prompt.Type == TypeInt || prompt.Type == TypeFloat) {
+		return nil, fmt.Errorf("%s (%s)", prompt.Name, prompt.Prompt)
+	}
+	if value == "" && prompt.Type == TypeBool {
+		return false, nil
+	}
+	val, err := ParseValue(prompt, value)
+	if err == nil {
+		err = Validate(prompt, val, answers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s (%s): %s", prompt.Name, prompt.Prompt, err)
+	}
+	return val, nil
+}
+
+// The value of the environment variable named by the Env of prompt, when it
+// is set.
+func envDefault(prompt Prompt) (string, bool) {
+	if prompt.Env == "" {
+		return "", false
+	}
+	return os.LookupEnv(prompt.Env)
+}
+
+// IsAsked evaluates the When expression of prompt against the answers given
+// so far.
+func IsAsked(prompt Prompt, answers map[string]interface{}) (bool, error) {
+	if strings.TrimSpace(prompt.When) == "" {
+		return true, nil
+	}
+	asked, err := isTrue(prompt.When, answers)
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("failed to evaluate when of prompt %s", prompt.Name))
+	}
+	return asked, nil
+}
+
+// Evaluate a condition with vars.  The condition may omit the surrounding
+// {{ }}.
+func isTrue(condition string, vars map[string]interface{}) (bool, error) {
+	if !strings.Contains(condition, "{{") {
+		condition = "{{ " + condition + " }}"
+	}
+	result, err := Render(condition, vars)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(result)) {
+	case "", "false", "0", "no", noValue:
+		return false, nil
+	}
+	return true, nil
+}
+
+// IsTemplated is true when the Default, Choices or Fallback of prompt depend
+// on a template expression.
+func IsTemplated(prompt Prompt) bool {
+	for _, choice := range prompt.Choices {
+		if isTemplatedChoice(choice) {
+			return true
+		}
+	}
+	return strings.Contains(FormatValue(prompt.Default), "{{") || strings.Contains(FormatValue(prompt.Fallback), "{{")
+}
+
+// ResolvePrompt renders the Default, Choices and Fallback of prompt that are
+// templates, such as "{{ .ProjectName | kebabcase }}", with the answers given
+// so far.  Variables without an answer render as an empty string.
+func ResolvePrompt(prompt Prompt, answers map[string]interface{}) (Prompt, error) {
+	resolve := func(value interface{}, field string) (interface{}, error) {
+		text, ok := value.(string)
+		if !ok || !strings.Contains(text, "{{") {
+			return value, nil
+		}
+		result, err := Render(text, answers)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to evaluate %s of prompt %s", field, prompt.Name))
+		}
+		return strings.ReplaceAll(result, noValue, ""), nil
+	}
+
+	var err error
+	resolved := prompt
+	if resolved.Default, err = resolve(prompt.Default, "default"); err != nil {
+		return Prompt{}, err
+	}
+	if resolved.Fallback, err = resolve(prompt.Fallback, "fallback"); err != nil {
+		return Prompt{}, err
+	}
+	resolved.Choices = make([]Choice, len(prompt.Choices))
+	for i, choice := range prompt.Choices {
+		resolved.Choices[i], err = resolveChoice(choice, func(text string) (string, error) {
+			value, err := resolve(text, "choices")
+			if err != nil {
+				return "", err
+			}
+			return value.(string), nil
+		})
+		if err != nil {
+			return Prompt{}, err
+		}
+	}
+	return resolved, checkSeparator(resolved)
+}
+
+// StaticPrompt resolves the templates of prompt that do not depend on any
+// answer, leaving the templates that cannot be resolved yet unevaluated.
+func StaticPrompt(prompt Prompt) Prompt {
+	resolve := func(value interface{}) interface{} {
+		text, ok := value.(string)
+		if !ok || !strings.Contains(text, "{{") {
+			return value
+		}
+		result, err := Render(text, map[string]interface{}{})
+		if err != nil || strings.Contains(result, noValue) {
+			return text
+		}
+		return result
+	}
+
+	static := prompt
+	static.Default = resolve(prompt.Default)
+	static.Fallback = resolve(prompt.Fallback)
+	static.Choices = make([]Choice, len(prompt.Choices))
+	for i, choice := range prompt.Choices {
+		static.Choices[i], _ = resolveChoice(choice, func(text string) (string, error) {
+			return resolve(text).(string), nil
+		})
+	}
+	return static
+}
+
+// Render content, such as an expression in prompts.toml, with vars.  The
+// renderer adds its own context to the map it is given, so vars is copied.
+func Render(content string, vars map[string]interface{}) (string, error) {
+	context := make(map[string]interface{}, len(vars))
+	for key, value := range vars {
+		context[key] = value
+	}
+	template, err := newRenderer(context)
+	if err != nil {
+		return "", err
+	}
+	return template.ProcessContent(content, "")
 }
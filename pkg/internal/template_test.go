@@ -94,7 +94,7 @@ func (c *consoleWithErrorHandling) ExpectEOF() {
 	}
 }
 
-func RunTest(t *testing.T, procedure func(expectConsole), test func(terminal.Stdio) (map[string]string, error), expected map[string]string) {
+func RunTest[T any](t *testing.T, procedure func(expectConsole), test func(terminal.Stdio) (T, error), expected T) {
 	t.Helper()
 	t.Parallel()
 
@@ -133,7 +133,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 	type TestCase struct {
 		prompts   []internal.Prompt
 		text      func(c expectConsole)
-		expected  map[string]string
+		expected  map[string]interface{}
 		arguments map[string]string
 	}
 	prompt := internal.Prompt{
@@ -143,10 +143,11 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 	selection := internal.Prompt{
 		Name:    "Duck",
 		Prompt:  "Make noise",
-		Choices: []string{"moo", "quack", "baa"},
+		Choices: internal.NewChoices("moo", "quack", "baa"),
 	}
 
 	duckQuack := map[string]string{"Duck": "quack"}
+	expectedQuack := map[string]interface{}{"Duck": "quack"}
 	testCases := []TestCase{
 		{
 			prompts: []internal.Prompt{prompt},
@@ -155,7 +156,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("")
 				c.ExpectEOF()
 			},
-			expected: map[string]string{"Duck": ""}},
+			expected: map[string]interface{}{"Duck": ""}},
 		{
 			prompts: []internal.Prompt{prompt},
 			text: func(c expectConsole) {
@@ -163,7 +164,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("quack")
 				c.ExpectEOF()
 			},
-			expected: duckQuack,
+			expected: expectedQuack,
 		},
 		{
 			prompts: []internal.Prompt{prompt},
@@ -171,7 +172,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("")
 				c.ExpectEOF()
 			},
-			expected:  duckQuack,
+			expected:  expectedQuack,
 			arguments: duckQuack,
 		},
 		// \x0d is Enter
@@ -181,7 +182,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("\x0d")
 				c.ExpectEOF()
 			},
-			expected:  duckQuack,
+			expected:  expectedQuack,
 			arguments: duckQuack,
 		},
 		{
@@ -191,7 +192,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("\x0d")
 				c.ExpectEOF()
 			},
-			expected: map[string]string{"Duck": "moo"},
+			expected: map[string]interface{}{"Duck": "moo"},
 		},
 		// \x1b\x5b\x42 is the terminal escape sequence for down arrow
 		{
@@ -201,7 +202,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("\x1b\x5b\x42\x0d")
 				c.ExpectEOF()
 			},
-			expected: duckQuack,
+			expected: expectedQuack,
 		},
 		{
 			prompts: []internal.Prompt{selection},
@@ -209,7 +210,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 				c.SendLine("")
 				c.ExpectEOF()
 			},
-			expected:  duckQuack,
+			expected:  expectedQuack,
 			arguments: duckQuack,
 		},
 	}
@@ -230,7 +231,7 @@ func testAskPrompts(t *testing.T, when spec.G, it spec.S) {
 					TArguments: currentCase.arguments,
 				}
 
-				test := func(stdio terminal.Stdio) (map[string]string, error) {
+				test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
 					return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
 				}
 				RunTest(t, currentCase.text, test, currentCase.expected)
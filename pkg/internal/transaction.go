@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/pkg/errors"
+)
+
+const (
+	stagingPrefix string = ".scafall-staging-"
+	projectDir    string = "project"
+	backupDir     string = "backup"
+)
+
+// Transaction renders a project into a staging directory beside the target
+// directory.  Files are only moved into the target directory on Commit, and a
+// failed Commit, or a Rollback, removes only the files and directories that
+// the Transaction created.  Files that are overwritten or removed are
+// restored.
+type Transaction struct {
+	target  string
+	staging string
+	project string
+	// paths in target to remove on Commit
+	removals []string
+	// paths created in target, in the order they were created
+	created []string
+	// overwritten paths in target and where their original is kept
+	backups map[string]string
+}
+
+// NewTransaction creates a Transaction writing to targetDir.
+func NewTransaction(targetDir string) (*Transaction, error) {
+	target, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transaction{target: target, backups: map[string]string{}}
+	parent := filepath.Dir(target)
+	if err := t.mkdirAll(parent); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to create %s", parent))
+	}
+	t.staging, err = os.MkdirTemp(parent, stagingPrefix)
+	if err != nil {
+		t.Rollback()
+		return nil, errors.Wrap(err, "failed to create staging directory")
+	}
+	t.project = filepath.Join(t.staging, projectDir)
+	if err := os.Mkdir(t.project, 0755); err != nil {
+		t.Rollback()
+		return nil, errors.Wrap(err, "failed to create staging directory")
+	}
+	return t, nil
+}
+
+// FS is the filesystem in which the project is rendered.
+func (t *Transaction) FS() billy.Filesystem {
+	return osfs.New(t.project)
+}
+
+// Target is the filesystem of the target directory, which must not be written
+// to directly.
+func (t *Transaction) Target() billy.Filesystem {
+	return osfs.New(t.target)
+}
+
+// Remove stages the removal of the file at path, relative to the target
+// directory, which is only removed on Commit.
+func (t *Transaction) Remove(path string) {
+	t.removals = append(t.removals, path)
+}
+
+// Commit moves the rendered project into the target directory and removes the
+// files staged for removal.  On failure the target directory is returned to
+// its previous state.
+func (t *Transaction) Commit() error {
+	if err := t.mkdirAll(t.target); err != nil {
+		t.Rollback()
+		return errors.Wrap(err, fmt.Sprintf("failed to create %s", t.target))
+	}
+
+	err := filepath.WalkDir(t.project, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.project, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		return t.move(rel, d)
+	})
+	if err != nil {
+		t.Rollback()
+		return errors.Wrap(err, "failed to move project into place")
+	}
+	for _, rel := range t.removals {
+		if err := t.remove(rel); err != nil {
+			t.Rollback()
+			return errors.Wrap(err, fmt.Sprintf("failed to remove %s", rel))
+		}
+	}
+
+	os.RemoveAll(t.staging)
+	t.removals = nil
+	t.created = nil
+	t.backups = map[string]string{}
+	return nil
+}
+
+// Rollback discards the staged project and removes everything the
+// Transaction created in the target directory.
+func (t *Transaction) Rollback() error {
+	var firstErr error
+	for path, backup := range t.backups {
+		if err := os.Rename(backup, path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if t.staging != "" {
+		os.RemoveAll(t.staging)
+	}
+	for i := len(t.created) - 1; i >= 0; i-- {
+		// os.Remove leaves any directory that is not empty in place
+		if err := os.Remove(t.created[i]); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	t.removals = nil
+	t.created = nil
+	t.backups = map[string]string{}
+	return firstErr
+}
+
+func (t *Transaction) move(rel string, d fs.DirEntry) error {
+	src := filepath.Join(t.project, rel)
+	dst := filepath.Join(t.target, rel)
+
+	existing, err := os.Lstat(dst)
+	if d.IsDir() {
+		if err == nil && existing.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		t.created = append(t.created, dst)
+		return nil
+	}
+
+	if err == nil {
+		if existing.IsDir() {
+			return fmt.Errorf("cannot replace directory %s with a file", dst)
+		}
+		if err := t.backup(rel, dst); err != nil {
+			return err
+		}
+	} else {
+		t.created = append(t.created, dst)
+	}
+	return os.Rename(src, dst)
+}
+
+// Move the file at rel in the target directory aside, so that it is restored
+// on Rollback.
+func (t *Transaction) remove(rel string) error {
+	dst := filepath.Join(t.target, rel)
+	if _, err := os.Lstat(dst); os.IsNotExist(err) {
+		return nil
+	}
+	return t.backup(rel, dst)
+}
+
+// Keep the file at dst, the path rel in the target directory, in the staging
+// directory until the Transaction is committed or rolled back.
+func (t *Transaction) backup(rel string, dst string) error {
+	backup := filepath.Join(t.staging, backupDir, rel)
+	if err := os.MkdirAll(filepath.Dir(backup), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(dst, backup); err != nil {
+		return err
+	}
+	t.backups[dst] = backup
+	return nil
+}
+
+// Create dir and any missing parents, recording each directory created.
+func (t *Transaction) mkdirAll(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	parent := filepath.Dir(dir)
+	if parent != dir {
+		if err := t.mkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	t.created = append(t.created, dir)
+	return nil
+}
@@ -0,0 +1,149 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// entries lists the names in dir
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+	items, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.Name())
+	}
+	return names
+}
+
+func testTransaction(t *testing.T, when spec.G, it spec.S) {
+	when("generating into a directory with content", func() {
+		var (
+			parent string
+			target string
+		)
+
+		it.Before(func() {
+			parent = t.TempDir()
+			target = filepath.Join(parent, "project")
+			require.Nil(t, os.MkdirAll(filepath.Join(target, "src"), 0755))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "README.md"), []byte("mine"), 0600))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "src", "main.go"), []byte("mine"), 0600))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "c"), []byte("mine"), 0600))
+		})
+
+		it("moves the project into place on commit", func() {
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "src/lib.go", []byte("generated"), 0600))
+			require.Nil(t, util.WriteFile(tx.FS(), "README.md", []byte("generated"), 0600))
+
+			_, err = os.Stat(filepath.Join(target, "src", "lib.go"))
+			require.True(t, os.IsNotExist(err))
+
+			require.Nil(t, tx.Commit())
+			content, err := os.ReadFile(filepath.Join(target, "src", "lib.go"))
+			require.Nil(t, err)
+			require.Equal(t, "generated", string(content))
+			content, err = os.ReadFile(filepath.Join(target, "src", "main.go"))
+			require.Nil(t, err)
+			require.Equal(t, "mine", string(content))
+			require.Equal(t, []string{"project"}, entries(t, parent))
+		})
+
+		it("leaves the directory untouched when rendering fails", func() {
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "src/lib.go", []byte("generated"), 0600))
+
+			require.Nil(t, tx.Rollback())
+			require.Equal(t, []string{"README.md", "c", "src"}, entries(t, target))
+			require.Equal(t, []string{"main.go"}, entries(t, filepath.Join(target, "src")))
+			require.Equal(t, []string{"project"}, entries(t, parent))
+		})
+
+		it("removes only created files when moving into place fails midway", func() {
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "README.md", []byte("generated"), 0600))
+			require.Nil(t, util.WriteFile(tx.FS(), "a/new.txt", []byte("generated"), 0600))
+			require.Nil(t, util.WriteFile(tx.FS(), "b.txt", []byte("generated"), 0600))
+			// c is a file in the target, so it cannot become a directory
+			require.Nil(t, util.WriteFile(tx.FS(), "c/d.txt", []byte("generated"), 0600))
+
+			require.NotNil(t, tx.Commit())
+			require.Equal(t, []string{"README.md", "c", "src"}, entries(t, target))
+			content, err := os.ReadFile(filepath.Join(target, "README.md"))
+			require.Nil(t, err)
+			require.Equal(t, "mine", string(content))
+			content, err = os.ReadFile(filepath.Join(target, "c"))
+			require.Nil(t, err)
+			require.Equal(t, "mine", string(content))
+			require.Equal(t, []string{"project"}, entries(t, parent))
+		})
+	})
+
+	when("removing files from a directory", func() {
+		var target string
+
+		it.Before(func() {
+			target = filepath.Join(t.TempDir(), "project")
+			require.Nil(t, os.MkdirAll(filepath.Join(target, "src"), 0755))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "README.md"), []byte("mine"), 0600))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "src", "old.go"), []byte("mine"), 0600))
+			require.Nil(t, os.WriteFile(filepath.Join(target, "c"), []byte("mine"), 0600))
+		})
+
+		it("removes the files on commit", func() {
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "src/new.go", []byte("generated"), 0600))
+			tx.Remove("src/old.go")
+			tx.Remove("missing.txt")
+
+			_, err = os.Stat(filepath.Join(target, "src", "old.go"))
+			require.Nil(t, err)
+			require.Nil(t, tx.Commit())
+			require.Equal(t, []string{"new.go"}, entries(t, filepath.Join(target, "src")))
+		})
+
+		it("restores the project when a removal fails", func() {
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "README.md", []byte("generated"), 0600))
+			require.Nil(t, util.WriteFile(tx.FS(), "new.txt", []byte("generated"), 0600))
+			tx.Remove("src/old.go")
+			// c is a file in the target, so it has no entries to remove
+			tx.Remove("c/d.txt")
+
+			require.NotNil(t, tx.Commit())
+			require.Equal(t, []string{"README.md", "c", "src"}, entries(t, target))
+			require.Equal(t, []string{"old.go"}, entries(t, filepath.Join(target, "src")))
+			content, err := os.ReadFile(filepath.Join(target, "README.md"))
+			require.Nil(t, err)
+			require.Equal(t, "mine", string(content))
+		})
+	})
+
+	when("generating into a new directory", func() {
+		it("removes the directories it created on failure", func() {
+			parent := t.TempDir()
+			target := filepath.Join(parent, "a", "b")
+
+			tx, err := internal.NewTransaction(target)
+			require.Nil(t, err)
+			require.Nil(t, util.WriteFile(tx.FS(), "file.txt", []byte("generated"), 0600))
+			require.Nil(t, tx.Rollback())
+
+			require.Equal(t, []string{}, entries(t, parent))
+		})
+	})
+}
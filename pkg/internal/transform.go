@@ -2,11 +2,12 @@ package internal
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-git/go-billy/v5"
 	"github.com/pkg/errors"
 
 	"github.com/buildpacks-community/scafall/pkg/internal/util"
@@ -29,17 +30,19 @@ func ReadFile(path string) (string, error) {
 	return string(buf), nil
 }
 
-func Apply(inputDir string, vars map[string]string, outputDir string) error {
+// Apply writes the template in inputFS to outputFS, replacing vars in the
+// path and content of each file.
+func Apply(inputFS fs.FS, vars map[string]interface{}, outputFS billy.Filesystem) error {
 	if vars == nil {
-		vars = map[string]string{}
+		vars = map[string]interface{}{}
 	}
-	files, err := findTransformableFiles(inputDir)
+	files, err := findTransformableFiles(inputFS)
 	if err != nil {
-		return fmt.Errorf("failed to find files in input folder: %s %s", inputDir, err)
+		return fmt.Errorf("failed to find files in input template: %s", err)
 	}
 
 	for _, file := range files {
-		err := file.Transform(inputDir, outputDir, vars)
+		err := file.Transform(inputFS, outputFS, vars)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to transform %s", file.FilePath))
 		}
@@ -48,30 +51,35 @@ func Apply(inputDir string, vars map[string]string, outputDir string) error {
 	return err
 }
 
-func findTransformableFiles(dir string) ([]SourceFile, error) {
+func findTransformableFiles(fsys fs.FS) ([]SourceFile, error) {
 	files := []SourceFile{}
-	err := filepath.WalkDir(dir, func(path string, info os.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, ".", func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if info.IsDir() && util.Contains(IgnoredDirectories, info.Name()) {
-			return filepath.SkipDir
+			return fs.SkipDir
 		}
 
 		if !info.IsDir() {
 			// Ignore all prompts.toml files and any top-level README.md
-			rootReadme := filepath.Join(dir, "README")
-			if util.Contains(IgnoredNames, info.Name()) || strings.HasPrefix(path, rootReadme) {
+			if util.Contains(IgnoredNames, info.Name()) || strings.HasPrefix(path, "README") {
 				return nil
 			}
 
-			relPath := strings.TrimPrefix(path, dir+"/")
-			if isTextfile(path) {
-				fileContent, err := ReadFile(path)
+			fileInfo, err := info.Info()
+			if err != nil {
+				return err
+			}
+			fileMode := fileInfo.Mode().Perm()
+			if isTextfile(fsys, path) {
+				fileContent, err := readFile(fsys, path)
 				if err != nil {
 					return err
 				}
-				fileMode := info.Type().Perm()
-				files = append(files, SourceFile{FilePath: relPath, FileContent: fileContent, FileMode: fileMode})
+				files = append(files, SourceFile{FilePath: path, FileContent: fileContent, FileMode: fileMode})
 			} else {
-				files = append(files, SourceFile{FilePath: relPath, FileContent: ""})
+				files = append(files, SourceFile{FilePath: path, FileContent: "", FileMode: fileMode})
 			}
 		}
 		return nil
@@ -80,11 +88,20 @@ func findTransformableFiles(dir string) ([]SourceFile, error) {
 	return files, err
 }
 
-func isTextfile(path string) bool {
-	fd, err := os.Open(path)
+func readFile(fsys fs.FS, path string) (string, error) {
+	buf, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file %s", path)
+	}
+	return string(buf), nil
+}
+
+func isTextfile(fsys fs.FS, path string) bool {
+	fd, err := fsys.Open(path)
 	if err != nil {
 		return false
 	}
+	defer fd.Close()
 	mtype, err := mimetype.DetectReader(fd)
 	if err != nil {
 		return false
@@ -1,10 +1,11 @@
 package internal_test
 
 import (
-	"os"
-	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/sclevine/spec"
 	h "github.com/stretchr/testify/assert"
 
@@ -14,27 +15,50 @@ import (
 func testApply(t *testing.T, when spec.G, it spec.S) {
 	when("Applying to a filesystem", func() {
 		it("correctly replaces strings in a filesytem", func() {
-			tmpDir := t.TempDir()
-			outputDir := t.TempDir()
-			err := os.MkdirAll(filepath.Join(tmpDir, "/{{.Foo}}/{{.Foo}}"), 0766)
+			inputFS := fstest.MapFS{
+				"{{.Foo}}/{{.Foo}}/{{.Foo}}.txt": &fstest.MapFile{Data: []byte("{{.Foo}}")},
+			}
+			outputFS := memfs.New()
+			vars := map[string]interface{}{"Foo": "Bar"}
+
+			err := internal.Apply(inputFS, vars, outputFS)
 			h.Nil(t, err)
-			f, err := os.Create(filepath.Join(tmpDir, "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt"))
+
+			bar, err := outputFS.Open("/Bar/Bar/Bar.txt")
 			h.Nil(t, err)
-			f.Write([]byte("{{.Foo}}"))
-			f.Close()
-			vars := map[string]string{"Foo": "Bar"}
+			h.NotNil(t, bar)
 
-			err = internal.Apply(tmpDir, vars, outputDir)
+			c, err := util.ReadFile(outputFS, "/Bar/Bar/Bar.txt")
 			h.Nil(t, err)
+			h.Contains(t, string(c), "Bar")
+		})
+
+		it("reads from any filesystem and leaves it unchanged", func() {
+			binary := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00}
+			inputFS := fstest.MapFS{
+				"{{.Foo}}/{{.Foo}}.txt": &fstest.MapFile{Data: []byte("{{.Foo}}"), Mode: 0644},
+				"{{.Foo}}/{{.Foo}}.png": &fstest.MapFile{Data: binary, Mode: 0755},
+				"prompts.toml":          &fstest.MapFile{Data: []byte("")},
+			}
+			outputFS := memfs.New()
+			vars := map[string]interface{}{"Foo": "Bar"}
 
-			bar, err := os.Open(filepath.Join(outputDir, "/Bar/Bar/Bar.txt"))
+			err := internal.Apply(inputFS, vars, outputFS)
 			h.Nil(t, err)
-			h.NotNil(t, bar)
 
-			var c string
-			c, err = internal.ReadFile(filepath.Join(outputDir, "/Bar/Bar/Bar.txt"))
+			c, err := util.ReadFile(outputFS, "Bar/Bar.txt")
 			h.Nil(t, err)
-			h.Contains(t, c, "Bar")
+			h.Equal(t, "Bar", string(c))
+			data, err := util.ReadFile(outputFS, "Bar/Bar.png")
+			h.Nil(t, err)
+			h.Equal(t, binary, data)
+			fi, err := outputFS.Stat("Bar/Bar.png")
+			h.Nil(t, err)
+			h.Equal(t, 0755, int(fi.Mode().Perm()))
+
+			h.Equal(t, binary, inputFS["{{.Foo}}/{{.Foo}}.png"].Data)
+			_, err = outputFS.Stat("prompts.toml")
+			h.NotNil(t, err)
 		})
 	})
 }
@@ -42,45 +66,40 @@ func testApply(t *testing.T, when spec.G, it spec.S) {
 func testApplyNoArgument(t *testing.T, when spec.G, it spec.S) {
 	when("Applying to a file without argument", func() {
 		it("does not replace the template variable", func() {
-			tmpDir := t.TempDir()
-			outputDir := t.TempDir()
-			testFile := filepath.Join(tmpDir, "test.txt")
 			content := "{{ .Foo }}"
-			os.WriteFile(testFile, []byte(content), 0600)
+			inputFS := fstest.MapFS{
+				"test.txt": &fstest.MapFile{Data: []byte(content), Mode: 0600},
+			}
+			outputFS := memfs.New()
 
-			err := internal.Apply(tmpDir, nil, outputDir)
+			err := internal.Apply(inputFS, nil, outputFS)
 			h.Nil(t, err)
 
-			c, err := internal.ReadFile(filepath.Join(outputDir, "test.txt"))
+			c, err := util.ReadFile(outputFS, "test.txt")
 			h.Nil(t, err)
-			h.Contains(t, c, content)
+			h.Contains(t, string(c), content)
 		})
 	})
 
 	when("Applying to a filesystem without argument", func() {
 		it("does not replace the template variable", func() {
-			tmpDir := t.TempDir()
-			outputDir := t.TempDir()
-			err := os.MkdirAll(filepath.Join(tmpDir, "/{{.Foo}}/{{.Foo}}"), 0766)
-			h.Nil(t, err)
-			f, err := os.Create(filepath.Join(tmpDir, "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt"))
-			h.Nil(t, err)
-			f.Write([]byte("{{.Foo}}"))
-			f.Close()
-			vars := map[string]string{"Bar": "bar"}
+			inputFS := fstest.MapFS{
+				"{{.Foo}}/{{.Foo}}/{{.Foo}}.txt": &fstest.MapFile{Data: []byte("{{.Foo}}")},
+			}
+			outputFS := memfs.New()
+			vars := map[string]interface{}{"Bar": "bar"}
 
-			err = internal.Apply(tmpDir, vars, outputDir)
+			err := internal.Apply(inputFS, vars, outputFS)
 			h.Nil(t, err)
 
-			fooTxt := filepath.Join(outputDir, "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt")
-			foo, err := os.Stat(fooTxt)
+			fooTxt := "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt"
+			foo, err := outputFS.Stat(fooTxt)
 			h.Nil(t, err)
 			h.NotNil(t, foo)
 
-			var c string
-			c, err = internal.ReadFile(filepath.Join(outputDir, "/{{.Foo}}/{{.Foo}}/{{.Foo}}.txt"))
+			c, err := util.ReadFile(outputFS, fooTxt)
 			h.Nil(t, err)
-			h.Contains(t, c, "{{.Foo}}")
+			h.Contains(t, string(c), "{{.Foo}}")
 		})
 	})
 }
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// UpdateResult lists the files changed by MergeProject.  Files listed in
+// Conflicts hold conflict markers, or are binary files that were kept as they
+// were.  Removed files are no longer part of the template and were not
+// changed since they were generated.
+type UpdateResult struct {
+	Created   []string
+	Updated   []string
+	Removed   []string
+	Conflicts []string
+}
+
+// MergeProject merges the changes between the base and updated renderings of
+// a template into the project in target, writing every changed file to
+// output.  Files removed from the template are only listed in the result, so
+// that the caller can remove them from target.
+func MergeProject(base billy.Filesystem, updated billy.Filesystem, target billy.Filesystem, output billy.Filesystem, oursLabel string, theirsLabel string) (UpdateResult, error) {
+	result := UpdateResult{}
+	paths, err := filePaths(base, updated)
+	if err != nil {
+		return result, err
+	}
+
+	for _, path := range paths {
+		baseContent, baseErr := util.ReadFile(base, path)
+		newContent, newErr := util.ReadFile(updated, path)
+		ours, oursErr := util.ReadFile(target, path)
+		inBase, inUpdated, inTarget := baseErr == nil, newErr == nil, oursErr == nil
+
+		switch {
+		case inBase && inUpdated && bytes.Equal(baseContent, newContent):
+			// unchanged in the template
+		case !inUpdated:
+			if inTarget && inBase && bytes.Equal(ours, baseContent) {
+				result.Removed = append(result.Removed, path)
+			}
+		case !inTarget:
+			// a file removed from the project stays removed
+			if !inBase {
+				if err := writeLike(updated, output, path, newContent); err != nil {
+					return result, err
+				}
+				result.Created = append(result.Created, path)
+			}
+		case bytes.Equal(ours, newContent):
+			// already up to date
+		case !isText(ours) || !isText(newContent) || (inBase && !isText(baseContent)):
+			result.Conflicts = append(result.Conflicts, path)
+		default:
+			merged, conflict := Merge3(string(baseContent), string(ours), string(newContent), oursLabel, theirsLabel)
+			if err := writeLike(updated, output, path, []byte(merged)); err != nil {
+				return result, err
+			}
+			if conflict {
+				result.Conflicts = append(result.Conflicts, path)
+			} else {
+				result.Updated = append(result.Updated, path)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Every file path in any of fsyss, sorted and without the RecordFile
+func filePaths(fsyss ...billy.Filesystem) ([]string, error) {
+	unique := map[string]bool{}
+	for _, fsys := range fsyss {
+		err := util.Walk(fsys, "/", func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel := strings.TrimPrefix(filepath.ToSlash(path), "/")
+			if rel != filepath.ToSlash(RecordFile) {
+				unique[rel] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	paths := make([]string, 0, len(unique))
+	for path := range unique {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Write content to path in dst with the mode of path in src
+func writeLike(src billy.Filesystem, dst billy.Filesystem, path string, content []byte) error {
+	info, err := src.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.WriteFile(dst, path, content, info.Mode().Perm())
+}
+
+func isText(content []byte) bool {
+	return strings.HasPrefix(mimetype.Detect(content).String(), "text")
+}
@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testRecord(t *testing.T, when spec.G, it spec.S) {
+	when("recording how a project was generated", func() {
+		it("reads the record it wrote", func() {
+			fsys := memfs.New()
+			record := internal.Record{
+				URL:     "https://example.com/template.git",
+				SubPath: "python",
+				Commit:  "3f2c1ab",
+				Answers: map[string]interface{}{"Name": "quack", "Port": int64(8080), "Docker": true},
+			}
+			err := internal.WriteRecord(fsys, record)
+			require.Nil(t, err)
+
+			read, err := internal.ReadRecord(fsys)
+			require.Nil(t, err)
+			require.Equal(t, record, read)
+		})
+
+		it("fails for a project without a record", func() {
+			_, err := internal.ReadRecord(memfs.New())
+			require.ErrorContains(t, err, "not generated by scafall")
+		})
+	})
+}
+
+func testMergeProject(t *testing.T, when spec.G, it spec.S) {
+	var (
+		base    billy.Filesystem
+		updated billy.Filesystem
+		target  billy.Filesystem
+		output  billy.Filesystem
+	)
+
+	it.Before(func() {
+		base, updated, target, output = memfs.New(), memfs.New(), memfs.New(), memfs.New()
+		write := func(fsys billy.Filesystem, files map[string]string) {
+			for path, content := range files {
+				require.Nil(t, util.WriteFile(fsys, path, []byte(content), 0644))
+			}
+		}
+		write(base, map[string]string{
+			"same.txt":     "same\n",
+			"template.txt": "1\n2\n3\n",
+			"conflict.txt": "base\n",
+			"removed.txt":  "removed\n",
+			"edited.txt":   "edited\n",
+			"deleted.txt":  "old\n",
+		})
+		write(updated, map[string]string{
+			"same.txt":     "same\n",
+			"template.txt": "1\n2\nthree\n",
+			"conflict.txt": "theirs\n",
+			"new.txt":      "new\n",
+			"deleted.txt":  "new\n",
+		})
+		write(target, map[string]string{
+			"same.txt":     "mine\n",
+			"template.txt": "one\n2\n3\n",
+			"conflict.txt": "mine\n",
+			"removed.txt":  "removed\n",
+			"edited.txt":   "mine\n",
+		})
+	})
+
+	when("merging a template update", func() {
+		it("writes the merged files to output", func() {
+			result, err := internal.MergeProject(base, updated, target, output, "local", "template")
+			require.Nil(t, err)
+			require.Equal(t, internal.UpdateResult{
+				Created:   []string{"new.txt"},
+				Updated:   []string{"template.txt"},
+				Removed:   []string{"removed.txt"},
+				Conflicts: []string{"conflict.txt"},
+			}, result)
+
+			all := []string{"same.txt", "template.txt", "conflict.txt", "new.txt", "removed.txt", "edited.txt", "deleted.txt"}
+			require.Equal(t, map[string]string{
+				"template.txt": "one\n2\nthree\n",
+				"conflict.txt": "<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> template\n",
+				"new.txt":      "new\n",
+			}, contents(output, all...))
+		})
+	})
+}
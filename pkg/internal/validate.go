@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pkg/errors"
+
+	"github.com/buildpacks-community/scafall/pkg/internal/util"
+)
+
+// ValueKey is the name of the value being validated in an Expression rule.
+const ValueKey string = "Value"
+
+// Rule is a validation rule for the value of a prompt, reported with
+// ErrorMessage when it is set.  A string must match Pattern and have between
+// MinLength and MaxLength characters, a number must lie between Min and Max,
+// and an Expression must render to a true value using the answers given so
+// far and the value as .Value.  The length of a multiselect value is the
+// number of choices selected, each of which must match Pattern.
+type Rule struct {
+	Pattern      string   `toml:"pattern,omitempty" json:"pattern,omitempty"`
+	MinLength    *int     `toml:"min_length,omitempty" json:"min_length,omitempty"`
+	MaxLength    *int     `toml:"max_length,omitempty" json:"max_length,omitempty"`
+	Min          *float64 `toml:"min,omitempty" json:"min,omitempty"`
+	Max          *float64 `toml:"max,omitempty" json:"max,omitempty"`
+	Expression   string   `toml:"expression,omitempty" json:"expression,omitempty"`
+	ErrorMessage string   `toml:"error_message,omitempty" json:"error_message,omitempty"`
+}
+
+// Validate checks value against the rules of prompt, including Required and
+// its Choices, returning the error of the first rule that is broken.
+func Validate(prompt Prompt, value interface{}, answers map[string]interface{}) error {
+	if prompt.Required && FormatValue(value) == "" {
+		return fmt.Errorf("%s is required", prompt.Name)
+	}
+	if err := checkChoices(prompt, value); err != nil {
+		return err
+	}
+	for _, rule := range prompt.Validate {
+		if err := rule.check(prompt.Name, value, answers); err != nil {
+			if rule.ErrorMessage != "" {
+				return fmt.Errorf("%s", rule.ErrorMessage)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Validator checks survey answers to prompt against its rules.
+func Validator(prompt Prompt, answers map[string]interface{}) survey.Validator {
+	return func(answer interface{}) error {
+		value, err := answerValue(prompt, answer)
+		if err != nil {
+			return err
+		}
+		return Validate(prompt, value, answers)
+	}
+}
+
+// Check that the rules of prompt are well formed.
+func checkRules(prompt Prompt) error {
+	for _, rule := range prompt.Validate {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("prompt %s has invalid pattern %s: %s", prompt.Name, rule.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// Check that each item of value is the value of one of the choices of prompt.
+// An empty value is checked by Required.
+func checkChoices(prompt Prompt, value interface{}) error {
+	if len(prompt.Choices) == 0 {
+		return nil
+	}
+	items := []string{FormatValue(value)}
+	if list, ok := value.([]string); ok {
+		items = list
+	}
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+		if !util.Contains(choiceValues(prompt.Choices), item) {
+			return fmt.Errorf("%s must be one of %s, not %q", prompt.Name, strings.Join(choiceValues(prompt.Choices), ", "), item)
+		}
+	}
+	return nil
+}
+
+func (r Rule) check(name string, value interface{}, answers map[string]interface{}) error {
+	items := []string{FormatValue(value)}
+	length := len([]rune(items[0]))
+	if list, ok := value.([]string); ok {
+		items = list
+		length = len(list)
+	}
+
+	if r.Pattern != "" {
+		pattern := regexp.MustCompile(r.Pattern)
+		for _, item := range items {
+			if !pattern.MatchString(item) {
+				return fmt.Errorf("%s must match %s", name, r.Pattern)
+			}
+		}
+	}
+	if r.MinLength != nil && length < *r.MinLength {
+		return fmt.Errorf("%s must have a length of at least %d", name, *r.MinLength)
+	}
+	if r.MaxLength != nil && length > *r.MaxLength {
+		return fmt.Errorf("%s must have a length of at most %d", name, *r.MaxLength)
+	}
+	if r.Min != nil || r.Max != nil {
+		number, err := strconv.ParseFloat(FormatValue(value), 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number", name)
+		}
+		if err := checkRange(Prompt{Name: name, Min: r.Min, Max: r.Max}, number); err != nil {
+			return err
+		}
+	}
+	if r.Expression != "" {
+		context := make(map[string]interface{}, len(answers)+1)
+		for key, answer := range answers {
+			context[key] = answer
+		}
+		context[ValueKey] = value
+		valid, err := isTrue(r.Expression, context)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to evaluate validation of %s", name))
+		}
+		if !valid {
+			return fmt.Errorf("%s is not valid", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package internal_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// a prompts.toml file with content
+func readCloser(content string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(content))
+}
+
+func testValidate(t *testing.T, when spec.G, it spec.S) {
+	three, five := 3, 5
+	one, ten := 1.0, 10.0
+	type TestCase struct {
+		title string
+		rule  internal.Rule
+		value interface{}
+		err   string
+	}
+	testCases := []TestCase{
+		{"matching pattern", internal.Rule{Pattern: "^[a-z]+$"}, "duck", ""},
+		{"pattern", internal.Rule{Pattern: "^[a-z]+$"}, "Duck", "Name must match ^[a-z]+$"},
+		{"custom message", internal.Rule{Pattern: "^[a-z]+$", ErrorMessage: "use lower case letters"}, "Duck", "use lower case letters"},
+		{"min length", internal.Rule{MinLength: &three}, "ab", "Name must have a length of at least 3"},
+		{"max length", internal.Rule{MaxLength: &five}, "quacks", "Name must have a length of at most 5"},
+		{"length of a list", internal.Rule{MaxLength: &three}, []string{"a", "b", "c", "d"}, "Name must have a length of at most 3"},
+		{"pattern of a list", internal.Rule{Pattern: "^[a-z]$"}, []string{"a", "B"}, "Name must match ^[a-z]$"},
+		{"range", internal.Rule{Min: &one, Max: &ten}, 11, "Name must be at most 10"},
+		{"number in range", internal.Rule{Min: &one, Max: &ten}, 1.5, ""},
+		{"non-number range", internal.Rule{Min: &one}, "one", "Name must be a number"},
+		{"valid expression", internal.Rule{Expression: `{{ hasPrefix .Org .Value }}`}, "github.com/quack/duck", ""},
+		{"expression", internal.Rule{Expression: `hasPrefix .Org .Value`, ErrorMessage: "must be in the organisation"}, "example.com/duck", "must be in the organisation"},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("checks "+testCase.title, func() {
+			prompt := internal.Prompt{Name: "Name", Validate: []internal.Rule{testCase.rule}}
+			err := internal.Validate(prompt, testCase.value, map[string]interface{}{"Org": "github.com/quack"})
+			if testCase.err == "" {
+				require.Nil(t, err)
+			} else {
+				require.EqualError(t, err, testCase.err)
+			}
+		})
+	}
+
+	it("checks required values", func() {
+		err := internal.Validate(internal.Prompt{Name: "Name", Required: true}, "", nil)
+		require.EqualError(t, err, "Name is required")
+	})
+
+	it("rejects invalid patterns", func() {
+		prompts := "[[prompt]]\nname = \"Name\"\nprompt = \"Name\"\n[[prompt.validate]]\npattern = \"[\"\n"
+		_, err := internal.NewTemplate(readCloser(prompts), nil)
+		require.ErrorContains(t, err, "prompt Name has invalid pattern")
+	})
+
+	it("validates arguments", func() {
+		prompts := "[[prompt]]\nname = \"Name\"\nprompt = \"Name\"\n[[prompt.validate]]\nmin_length = 3\nerror_message = \"too short\"\n"
+		template, err := internal.NewTemplate(readCloser(prompts), map[string]string{"Name": "ab"})
+		require.Nil(t, err)
+		_, err = template.Ask()
+		require.EqualError(t, err, "invalid argument Name: too short")
+	})
+
+	it("asks again until the answer is valid", func() {
+		prompt := internal.Prompt{
+			Name:     "Name",
+			Prompt:   "Project name",
+			Validate: []internal.Rule{{Pattern: "^[a-z]+$", ErrorMessage: "use lower case letters"}},
+		}
+		question := internal.NewQuestion(prompt)
+		template := internal.TemplateImpl{
+			TPrompts:   internal.Prompts{Prompts: []internal.Prompt{prompt}},
+			TQuestions: []*survey.Question{&question},
+		}
+		procedure := func(c expectConsole) {
+			c.ExpectString("Project name")
+			c.SendLine("Duck")
+			c.ExpectString("use lower case letters")
+			c.SendLine("duck")
+			c.ExpectEOF()
+		}
+		test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+			return template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+		}
+		RunTest(t, procedure, test, map[string]interface{}{"Name": "duck"})
+	})
+}
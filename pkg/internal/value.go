@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2/core"
+)
+
+// The type of a prompt decides the question asked and the type of its value.
+const (
+	TypeString      string = "string"
+	TypeBool        string = "bool"
+	TypeInt         string = "int"
+	TypeFloat       string = "float"
+	TypeMultiSelect string = "multiselect"
+	TypePassword    string = "password"
+)
+
+var PromptTypes = []string{TypeString, TypeBool, TypeInt, TypeFloat, TypeMultiSelect, TypePassword}
+
+// ValueSeparator separates the choices of a multiselect value given as a
+// string, so no choice of a multiselect may contain it.
+const ValueSeparator = ","
+
+// ParseValue converts value, such as an argument given on the command line,
+// into the type of prompt.  A multiselect value is a comma separated list.
+// The label of a choice is converted into its value.
+func ParseValue(prompt Prompt, value string) (interface{}, error) {
+	if prompt.Type != TypeMultiSelect {
+		value = choiceValue(prompt.Choices, value)
+	}
+	switch prompt.Type {
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, not %q", prompt.Name, value)
+		}
+		return b, nil
+	case TypeInt:
+		i, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, not %q", prompt.Name, value)
+		}
+		return i, checkRange(prompt, float64(i))
+	case TypeFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, not %q", prompt.Name, value)
+		}
+		return f, checkRange(prompt, f)
+	case TypeMultiSelect:
+		selected := []string{}
+		for _, v := range strings.Split(value, ValueSeparator) {
+			if v = strings.TrimSpace(v); v != "" {
+				selected = append(selected, choiceValue(prompt.Choices, v))
+			}
+		}
+		return selected, nil
+	default:
+		return value, nil
+	}
+}
+
+// FormatValue is the inverse of ParseValue.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ValueSeparator)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = FormatValue(item)
+		}
+		return strings.Join(items, ValueSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Convert the answer to a survey question into the type of prompt.
+func answerValue(prompt Prompt, answer interface{}) (interface{}, error) {
+	switch prompt.Type {
+	case TypeBool:
+		b := false
+		err := core.WriteAnswer(&b, prompt.Name, answer)
+		return b, err
+	case TypeMultiSelect:
+		selected := []string{}
+		err := core.WriteAnswer(&selected, prompt.Name, answer)
+		for i, label := range selected {
+			selected[i] = choiceValue(prompt.Choices, label)
+		}
+		return selected, err
+	case TypeInt, TypeFloat:
+		s := ""
+		if err := core.WriteAnswer(&s, prompt.Name, answer); err != nil {
+			return nil, err
+		}
+		return ParseValue(prompt, s)
+	default:
+		s := ""
+		err := core.WriteAnswer(&s, prompt.Name, answer)
+		return choiceValue(prompt.Choices, s), err
+	}
+}
+
+func checkRange(prompt Prompt, value float64) error {
+	if prompt.Min != nil && value < *prompt.Min {
+		return fmt.Errorf("%s must be at least %v", prompt.Name, *prompt.Min)
+	}
+	if prompt.Max != nil && value > *prompt.Max {
+		return fmt.Errorf("%s must be at most %v", prompt.Name, *prompt.Max)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testParseValue(t *testing.T, when spec.G, it spec.S) {
+	min, max := 1.0, 10.0
+	type TestCase struct {
+		prompt   internal.Prompt
+		value    string
+		expected interface{}
+		err      string
+	}
+	testCases := []TestCase{
+		{internal.Prompt{Name: "S"}, "text", "text", ""},
+		{internal.Prompt{Name: "B", Type: internal.TypeBool}, "true", true, ""},
+		{internal.Prompt{Name: "B", Type: internal.TypeBool}, "yes", nil, "B must be true or false"},
+		{internal.Prompt{Name: "I", Type: internal.TypeInt}, "42", 42, ""},
+		{internal.Prompt{Name: "I", Type: internal.TypeInt}, "4.2", nil, "I must be an integer"},
+		{internal.Prompt{Name: "I", Type: internal.TypeInt, Min: &min, Max: &max}, "11", nil, "I must be at most 10"},
+		{internal.Prompt{Name: "F", Type: internal.TypeFloat, Min: &min}, "0.5", nil, "F must be at least 1"},
+		{internal.Prompt{Name: "F", Type: internal.TypeFloat}, "0.5", 0.5, ""},
+		{internal.Prompt{Name: "M", Type: internal.TypeMultiSelect}, "a, b", []string{"a", "b"}, ""},
+		{internal.Prompt{Name: "M", Type: internal.TypeMultiSelect}, "", []string{}, ""},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("parses "+testCase.value+" as "+testCase.prompt.Name, func() {
+			value, err := internal.ParseValue(testCase.prompt, testCase.value)
+			if testCase.err != "" {
+				require.ErrorContains(t, err, testCase.err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, value)
+			if testCase.value != "" {
+				reparsed, err := internal.ParseValue(testCase.prompt, internal.FormatValue(value))
+				require.Nil(t, err)
+				require.Equal(t, value, reparsed)
+			}
+		})
+	}
+}
+
+func testAskTypedPrompts(t *testing.T, when spec.G, it spec.S) {
+	type TestCase struct {
+		title    string
+		prompt   internal.Prompt
+		text     func(c expectConsole)
+		expected interface{}
+	}
+	testCases := []TestCase{
+		{
+			"confirm",
+			internal.Prompt{Name: "Value", Prompt: "Use docker", Type: internal.TypeBool},
+			func(c expectConsole) {
+				c.ExpectString("Use docker")
+				c.SendLine("y")
+				c.ExpectEOF()
+			},
+			true,
+		},
+		{
+			"confirm default",
+			internal.Prompt{Name: "Value", Prompt: "Use docker", Type: internal.TypeBool, Default: true},
+			func(c expectConsole) {
+				c.ExpectString("Use docker")
+				c.SendLine("")
+				c.ExpectEOF()
+			},
+			true,
+		},
+		{
+			"int",
+			internal.Prompt{Name: "Value", Prompt: "Port", Type: internal.TypeInt, Default: int64(8080)},
+			func(c expectConsole) {
+				c.ExpectString("Port")
+				c.SendLine("")
+				c.ExpectEOF()
+			},
+			8080,
+		},
+		{
+			"invalid int",
+			internal.Prompt{Name: "Value", Prompt: "Port", Type: internal.TypeInt},
+			func(c expectConsole) {
+				c.ExpectString("Port")
+				c.SendLine("http")
+				c.ExpectString("Value must be an integer")
+				c.SendLine("80")
+				c.ExpectEOF()
+			},
+			80,
+		},
+		// \x1b\x5b\x42 is the terminal escape sequence for down arrow
+		{
+			"multiselect",
+			internal.Prompt{Name: "Value", Prompt: "Features", Type: internal.TypeMultiSelect, Choices: internal.NewChoices("logging", "metrics", "tracing")},
+			func(c expectConsole) {
+				c.ExpectString("Features")
+				c.Send(" \x1b\x5b\x42\x1b\x5b\x42 ")
+				c.SendLine("")
+				c.ExpectEOF()
+			},
+			[]string{"logging", "tracing"},
+		},
+		{
+			"password",
+			internal.Prompt{Name: "Value", Prompt: "Token", Type: internal.TypePassword},
+			func(c expectConsole) {
+				c.ExpectString("Token")
+				c.SendLine("secret")
+				c.ExpectEOF()
+			},
+			"secret",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("asks a "+testCase.title+" question", func() {
+			question := internal.NewQuestion(testCase.prompt)
+			template := internal.TemplateImpl{
+				TPrompts:   internal.Prompts{Prompts: []internal.Prompt{testCase.prompt}},
+				TQuestions: []*survey.Question{&question},
+			}
+			test := func(stdio terminal.Stdio) (interface{}, error) {
+				answers, err := template.Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+				return answers["Value"], err
+			}
+			RunTest(t, testCase.text, test, testCase.expected)
+		})
+	}
+
+	it("parses arguments into the type of the prompt", func() {
+		template := internal.TemplateImpl{
+			TPrompts: internal.Prompts{Prompts: []internal.Prompt{
+				{Name: "UseDocker", Prompt: "Use docker", Type: internal.TypeBool},
+				{Name: "Port", Prompt: "Port", Type: internal.TypeInt},
+			}},
+			TArguments: map[string]string{"UseDocker": "false", "Port": "80", "Other": "x"},
+		}
+		answers, err := template.Ask()
+		require.Nil(t, err)
+		require.Equal(t, map[string]interface{}{"UseDocker": false, "Port": 80, "Other": "x"}, answers)
+	})
+}
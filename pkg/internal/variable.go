@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Variable is a value computed from the answers to prompts, and from other
+// variables, by a template expression such as
+// "{{ .ProjectName | snakecase | lower }}".  Variables are never prompted for.
+type Variable struct {
+	Name  string `toml:"name" json:"name"`
+	Value string `toml:"value" json:"value"`
+}
+
+var reference = regexp.MustCompile(`\.(\w+)`)
+
+// OrderVariables sorts variables so that each follows the variables it refers
+// to, reporting any circular reference.
+func OrderVariables(variables []Variable) ([]Variable, error) {
+	byName := map[string]Variable{}
+	for _, v := range variables {
+		byName[v.Name] = v
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	ordered := []Variable{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+			cycle := append(path[start:], name)
+			return fmt.Errorf("circular reference between variables %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		v := byName[name]
+		for _, match := range reference.FindAllStringSubmatch(v.Value, -1) {
+			if _, ok := byName[match[1]]; ok {
+				if err := visit(match[1], append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, v)
+		return nil
+	}
+
+	for _, v := range variables {
+		if err := visit(v.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// ComputeVariables adds the value of each variable to answers.
+func ComputeVariables(variables []Variable, answers map[string]interface{}) error {
+	ordered, err := OrderVariables(variables)
+	if err != nil {
+		return err
+	}
+	for _, v := range ordered {
+		value, err := Render(v.Value, answers)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to compute variable %s", v.Name))
+		}
+		answers[v.Name] = strings.ReplaceAll(value, noValue, "")
+	}
+	return nil
+}
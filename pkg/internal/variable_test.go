@@ -0,0 +1,65 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testVariables(t *testing.T, when spec.G, it spec.S) {
+	when("computing variables", func() {
+		it("computes variables after the variables they refer to", func() {
+			variables := []internal.Variable{
+				{Name: "Package", Value: "{{ .Module }}.app"},
+				{Name: "Module", Value: "{{ .ProjectName | snakecase | lower }}"},
+			}
+			answers := map[string]interface{}{"ProjectName": "MyDuck"}
+			err := internal.ComputeVariables(variables, answers)
+			require.Nil(t, err)
+			require.Equal(t, map[string]interface{}{"ProjectName": "MyDuck", "Module": "my_duck", "Package": "my_duck.app"}, answers)
+		})
+
+		it("reports circular references", func() {
+			variables := []internal.Variable{
+				{Name: "A", Value: "{{ .B }}"},
+				{Name: "B", Value: "{{ .C }}"},
+				{Name: "C", Value: "{{ .A }}"},
+			}
+			_, err := internal.OrderVariables(variables)
+			require.EqualError(t, err, "circular reference between variables A -> B -> C -> A")
+		})
+
+		it("reports variables that refer to themselves", func() {
+			_, err := internal.OrderVariables([]internal.Variable{{Name: "A", Value: "{{ .A }}"}})
+			require.EqualError(t, err, "circular reference between variables A -> A")
+		})
+	})
+
+	when("reading variables from a prompts file", func() {
+		it("computes variables from the answers", func() {
+			prompts := "[[prompt]]\nname = \"Name\"\nprompt = \"Name\"\n[[variable]]\nname = \"Upper\"\nvalue = \"{{ .Name | upper }}\"\n"
+			template, err := internal.NewTemplate(readCloser(prompts), map[string]string{"Name": "duck"})
+			require.Nil(t, err)
+			require.Equal(t, []internal.Variable{{Name: "Upper", Value: "{{ .Name | upper }}"}}, template.Variables())
+
+			answers, err := template.Ask()
+			require.Nil(t, err)
+			require.Equal(t, "DUCK", answers["Upper"])
+		})
+
+		it("rejects variables named like a prompt", func() {
+			prompts := "[[prompt]]\nname = \"Name\"\nprompt = \"Name\"\n[[variable]]\nname = \"Name\"\nvalue = \"x\"\n"
+			_, err := internal.NewTemplate(readCloser(prompts), nil)
+			require.EqualError(t, err, "variable Name has the same name as a prompt or variable")
+		})
+
+		it("rejects circular references", func() {
+			prompts := "[[variable]]\nname = \"A\"\nvalue = \"{{ .B }}\"\n[[variable]]\nname = \"B\"\nvalue = \"{{ .A }}\"\n"
+			_, err := internal.NewTemplate(readCloser(prompts), nil)
+			require.ErrorContains(t, err, "circular reference")
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/sclevine/spec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+func testIsAsked(t *testing.T, when spec.G, it spec.S) {
+	answers := map[string]interface{}{"BuildTool": "gradle", "UseDocker": false, "Count": 2}
+	type TestCase struct {
+		expression string
+		expected   bool
+	}
+	testCases := []TestCase{
+		{"", true},
+		{`{{ eq .BuildTool "gradle" }}`, true},
+		{`{{ eq .BuildTool "maven" }}`, false},
+		{`eq .BuildTool "gradle"`, true},
+		{"{{ .UseDocker }}", false},
+		{"not .UseDocker", true},
+		{"gt .Count 1", true},
+		{"{{ .Unset }}", false},
+		{`{{ if hasKey . "Unset" }}true{{ end }}`, false},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		it("evaluates "+testCase.expression, func() {
+			asked, err := internal.IsAsked(internal.Prompt{Name: "P", When: testCase.expression}, answers)
+			require.Nil(t, err)
+			require.Equal(t, testCase.expected, asked)
+		})
+	}
+
+	it("reports invalid expressions", func() {
+		_, err := internal.IsAsked(internal.Prompt{Name: "P", When: "{{ nosuchfunction }}"}, answers)
+		require.ErrorContains(t, err, "failed to evaluate when of prompt P")
+	})
+}
+
+func testAskConditionalPrompts(t *testing.T, when spec.G, it spec.S) {
+	prompts := []internal.Prompt{
+		{Name: "BuildTool", Prompt: "Build tool", Choices: internal.NewChoices("maven", "gradle")},
+		{Name: "GradleDsl", Prompt: "Gradle DSL", Choices: internal.NewChoices("kotlin", "groovy"), When: `eq .BuildTool "gradle"`},
+		{Name: "MavenWrapper", Prompt: "Maven wrapper", Type: internal.TypeBool, When: `eq .BuildTool "maven"`, Fallback: false},
+	}
+	template := func(arguments map[string]string) internal.TemplateImpl {
+		questions := []*survey.Question{}
+		for _, p := range prompts {
+			if _, ok := arguments[p.Name]; !ok {
+				q := internal.NewQuestion(p)
+				questions = append(questions, &q)
+			}
+		}
+		return internal.TemplateImpl{TPrompts: internal.Prompts{Prompts: prompts}, TQuestions: questions, TArguments: arguments}
+	}
+
+	it("skips prompts using the fallback", func() {
+		answers, err := template(map[string]string{"BuildTool": "gradle", "GradleDsl": "kotlin"}).Ask()
+		require.Nil(t, err)
+		require.Equal(t, map[string]interface{}{"BuildTool": "gradle", "GradleDsl": "kotlin", "MavenWrapper": false}, answers)
+	})
+
+	it("asks prompts depending on earlier answers", func() {
+		procedure := func(c expectConsole) {
+			c.ExpectString("Build tool")
+			c.SendLine("")
+			c.ExpectString("Maven wrapper")
+			c.SendLine("y")
+			c.ExpectEOF()
+		}
+		test := func(stdio terminal.Stdio) (map[string]interface{}, error) {
+			return template(nil).Ask(survey.WithStdio(stdio.In, stdio.Out, stdio.Err))
+		}
+		RunTest(t, procedure, test, map[string]interface{}{"BuildTool": "maven", "MavenWrapper": true})
+	})
+}
@@ -0,0 +1,25 @@
+package scafall
+
+import (
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// Plan lists what Scaffold would do to every file of the project.
+type Plan = internal.Plan
+
+// PlannedFile is the action, mode and size of one file in a Plan.
+type PlannedFile = internal.PlannedFile
+
+// PlanAction is what happens to a single file of the project.
+type PlanAction = internal.PlanAction
+
+const (
+	// A new file is written
+	ActionCreate = internal.ActionCreate
+	// An existing file is replaced
+	ActionOverwrite = internal.ActionOverwrite
+	// An existing file is kept
+	ActionSkip = internal.ActionSkip
+	// An existing file conflicts and the ConflictPolicy fails or prompts
+	ActionConflict = internal.ActionConflict
+)
@@ -0,0 +1,52 @@
+package scafall
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// Prompter asks the user the questions of a template, which project template
+// of a collection to use and what to do with conflicting files.  Implement
+// Prompter to present the questions in another user interface.  The Name of
+// a question is the name of the prompt it answers, "template" for the choice
+// of project template, the path of a conflicting file or "review" for the
+// review of the answers.
+type Prompter = internal.Prompter
+
+// TextQuestion asks for free text, which is not echoed when it is Secret.
+type TextQuestion = internal.TextQuestion
+
+// SelectQuestion asks for one of its Options.
+type SelectQuestion = internal.SelectQuestion
+
+// ConfirmQuestion asks for yes or no.
+type ConfirmQuestion = internal.ConfirmQuestion
+
+// MultiSelectQuestion asks for any of its Options.
+type MultiSelectQuestion = internal.MultiSelectQuestion
+
+// ScriptedPrompter answers each question from a map of answers by the Name of
+// the question, for tests.  Questions without an answer take their default.
+type ScriptedPrompter = internal.ScriptedPrompter
+
+// SurveyPrompter asks questions on the terminal, passing opts to survey.
+func SurveyPrompter(opts ...survey.AskOpt) Prompter {
+	return internal.NewSurveyPrompter(opts...)
+}
+
+// Ask the questions with prompter in place of the terminal.  With
+// WithNonInteractive nothing is asked and the defaults are taken instead.
+func WithPrompter(prompter Prompter) Option {
+	return func(s *Scafall) {
+		s.Prompter = prompter
+	}
+}
+
+// The Prompter of s, or a survey prompter when it is nil.
+func (s *Scafall) prompter() Prompter {
+	if s.Prompter == nil {
+		return internal.NewSurveyPrompter()
+	}
+	return s.Prompter
+}
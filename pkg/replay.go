@@ -0,0 +1,49 @@
+package scafall
+
+import (
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5/osfs"
+
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// NewScafallFromRecord creates a Scafall that generates the project in
+// projectDir again, from the template revision and answers recorded in it,
+// without asking the user.  The project is generated in projectDir unless
+// opts give another output folder, and Arguments given in opts override the
+// recorded answers.  Passwords are never recorded, so they are taken from
+// their defaults or the Arguments.
+//
+// Files edited since the project was generated are backed up before they are
+// replaced, see ConflictBackup, unless opts give another ConflictPolicy.
+func NewScafallFromRecord(projectDir string, opts ...Option) (Scafall, error) {
+	record, err := internal.ReadRecord(osfs.New(projectDir))
+	if err != nil {
+		return Scafall{}, err
+	}
+
+	recorded := []Option{
+		WithOutputFolder(projectDir),
+		WithSubPath(filepath.Join(record.SubPath, record.Template)),
+		WithNonInteractive(),
+		WithConflictPolicy(ConflictBackup),
+	}
+	if record.Commit != "" {
+		recorded = append(recorded, WithRef(record.Commit))
+	}
+	s, err := NewScafall(record.URL, append(recorded, opts...)...)
+	if err != nil {
+		return Scafall{}, err
+	}
+
+	arguments := map[string]string{}
+	for name, value := range record.Answers {
+		arguments[name] = internal.FormatValue(value)
+	}
+	for name, value := range s.Arguments {
+		arguments[name] = value
+	}
+	s.Arguments = arguments
+	return s, nil
+}
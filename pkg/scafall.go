@@ -5,24 +5,77 @@ package scafall
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/buildpacks-community/scafall/pkg/internal"
 
-	"github.com/AlecAivazis/survey/v2"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
 )
 
 // Scafall allows programmatic control over the default values for variables.
 // Any provided Arguments cause prompts for the same variable name to be skipped.
+// Commit holds the SHA of the template revision used once a git template has
+// been fetched.
+//
+// Git templates are kept in a cache under CacheDir, which is reused across
+// runs.  A cached branch or tag is refreshed once it is older than
+// CacheMaxAge, and when Offline is set only cached templates are used.  Auth
+// provides the credentials for private git repositories.  When TemplateFS is
+// set the template is read from it instead of the URL, and when OutputFS is set
+// the project is written to it instead of the OutputFolder.  Existing files
+// that differ from the generated files are handled by the ConflictPolicy.
+// Arguments that answer no prompt of the template are reported as warnings,
+// or as an error when Strict is set.
+//
+// The template and answers are recorded in the project unless NoRecord is
+// set, see NewScafallFromRecord.
+//
+// When NonInteractive is set nothing is asked: each prompt takes its default
+// and a template must be chosen from a collection with the SubPath.  It is not
+// set by default, the scafall command sets it when stdin is not a terminal.
+//
+// Answers, or the AnswersFile, hold further arguments as a TOML, YAML or JSON
+// table, and environment variables such as SCAFALL_ARG_ProjectName give
+// arguments by their exact name.  The Arguments take precedence over the
+// environment, which takes precedence over the Answers.  An AnswersFile of
+// "-" is read from stdin.  The answers of the user Config come last, see
+// WithConfig.
+//
+// The Prompter asks the questions, on the terminal with survey when it is
+// nil.
 type Scafall struct {
-	URL          string
-	Arguments    map[string]string
-	OutputFolder string
-	SubPath      string
-	CloneCache   string
+	URL            string
+	Ref            string
+	Arguments      map[string]string
+	Answers        io.Reader
+	AnswersFile    string
+	OutputFolder   string
+	SubPath        string
+	Checksum       string
+	CloneCache     string
+	Commit         string
+	CacheDir       string
+	CacheMaxAge    time.Duration
+	Offline        bool
+	Auth           Auth
+	TemplateFS     fs.FS
+	OutputFS       billy.Filesystem
+	ConflictPolicy ConflictPolicy
+	Strict         bool
+	NonInteractive bool
+	NoRecord       bool
+	Config         Config
+	Prompter       Prompter
+	cloneRoot      string
+	release        func()
+	checkout       bool
 }
 
 type Option func(*Scafall)
@@ -41,6 +94,59 @@ func WithArguments(arguments map[string]string) Option {
 	}
 }
 
+// Never ask the user, taking the defaults of prompts instead.  Prompts
+// without a default are reported in a single error.
+func WithNonInteractive() Option {
+	return func(s *Scafall) {
+		s.NonInteractive = true
+	}
+}
+
+// Do not record the template and answers in the generated project.
+func WithoutRecord() Option {
+	return func(s *Scafall) {
+		s.NoRecord = true
+	}
+}
+
+// Read further arguments from r, a TOML, YAML or JSON table of values.
+func WithAnswers(r io.Reader) Option {
+	return func(s *Scafall) {
+		s.Answers = r
+	}
+}
+
+// Read further arguments from the TOML, YAML or JSON file at path, or from
+// stdin when path is "-".
+func WithAnswersFile(path string) Option {
+	return func(s *Scafall) {
+		s.AnswersFile = path
+	}
+}
+
+// Write the output project to fsys, rather than the output folder, for example
+// a memfs to inspect the project before writing it to disk.
+func WithOutputFS(fsys billy.Filesystem) Option {
+	return func(s *Scafall) {
+		s.OutputFS = fsys
+	}
+}
+
+// Handle existing files in the output that differ from the generated files
+// according to policy.
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(s *Scafall) {
+		s.ConflictPolicy = policy
+	}
+}
+
+// Fail, rather than warn, when an argument answers no prompt of the template.
+func WithStrict() Option {
+	return func(s *Scafall) {
+		s.Strict = true
+	}
+}
+
 // Use a sub folder within the template repository as the source for a template.
 func WithSubPath(subPath string) Option {
 	return func(s *Scafall) {
@@ -48,18 +154,77 @@ func WithSubPath(subPath string) Option {
 	}
 }
 
+// Use a git branch, tag or commit SHA of the template repository.
+func WithRef(ref string) Option {
+	return func(s *Scafall) {
+		s.Ref = ref
+	}
+}
+
+// Cache git templates in dir.  An empty dir disables caching.
+func WithCacheDir(dir string) Option {
+	return func(s *Scafall) {
+		s.CacheDir = dir
+	}
+}
+
+// Refresh cached branches and tags once they are older than maxAge.
+func WithCacheMaxAge(maxAge time.Duration) Option {
+	return func(s *Scafall) {
+		s.CacheMaxAge = maxAge
+	}
+}
+
+// Only use templates that are already cached.
+func WithOffline() Option {
+	return func(s *Scafall) {
+		s.Offline = true
+	}
+}
+
+// Require an archive template to have the given sha256 checksum.
+func WithChecksum(checksum string) Option {
+	return func(s *Scafall) {
+		s.Checksum = checksum
+	}
+}
+
+// Resolve credentials for private template repositories using auth.
+func WithAuth(auth Auth) Option {
+	return func(s *Scafall) {
+		s.Auth = auth
+	}
+}
+
+// Read the template, or collection of templates, from fsys rather than the
+// url given to NewScafall.  The files in fsys are read but never modified, so
+// an embed.FS may be used.
+func WithTemplateFS(fsys fs.FS) Option {
+	return func(s *Scafall) {
+		s.TemplateFS = fsys
+	}
+}
+
 // Create a new Scafall with the given options.  The input url can either point
-// to a project template or a collection of project templates.
+// to a project template or a collection of project templates, held in a local
+// directory, a git repository or a tar.gz, tgz, tar or zip archive.  A url of the
+// form url@ref selects a git branch, tag or commit unless WithRef is given.
 func NewScafall(url string, opts ...Option) (Scafall, error) {
 	var (
 		defaultArguments    = map[string]string{}
 		defaultOutputFolder = "."
 	)
 
+	url, ref := internal.SplitRef(url)
 	s := Scafall{
-		URL:          url,
-		Arguments:    defaultArguments,
-		OutputFolder: defaultOutputFolder,
+		URL:            url,
+		Ref:            ref,
+		Arguments:      defaultArguments,
+		OutputFolder:   defaultOutputFolder,
+		CacheDir:       DefaultCacheDir(),
+		CacheMaxAge:    DefaultCacheMaxAge,
+		Auth:           DefaultAuth(),
+		ConflictPolicy: ConflictFail,
 	}
 
 	for _, opt := range opts {
@@ -69,75 +234,399 @@ func NewScafall(url string, opts ...Option) (Scafall, error) {
 	return s, nil
 }
 
-// Scaffold creates an output project.
-func (s Scafall) Scaffold() error {
-	err := s.clone()
+// Scaffold creates an output project.  The project is rendered beside the
+// OutputFolder and only moved into it once rendering succeeds, so a failure
+// leaves the OutputFolder as it was.  Existing files that differ from the
+// rendered files are handled according to the ConflictPolicy before any file
+// is written.  Unless NoRecord is set, the template and answers are recorded
+// in the project, so that it can be updated or generated again later.  An
+// existing record that differs is a conflict like any other file.
+func (s *Scafall) Scaffold() error {
+	defer s.removeClone()
+	if err := s.readArguments(); err != nil {
+		return err
+	}
+	inFs, template, err := s.chooseTemplate()
 	if err != nil {
-		s.cleanUp()
 		return err
 	}
-	inFs := s.CloneCache
-	if isCollection, options := internal.IsCollection(inFs); isCollection {
-		question := survey.Select{
-			Message: "choose a project template",
-			Options: options,
+	if err := s.checkArguments(inFs); err != nil {
+		return err
+	}
+
+	if s.NonInteractive && s.ConflictPolicy == ConflictPrompt {
+		return fmt.Errorf("conflicts cannot be resolved with %s in non-interactive mode", ConflictPrompt)
+	}
+
+	if s.OutputFS != nil {
+		staged := memfs.New()
+		answers, err := internal.Create(inFs, s.input(s.Arguments), staged)
+		if err != nil {
+			return err
 		}
-		template := ""
-		err := survey.AskOne(&question, &template, survey.WithValidator(survey.Required))
+		if !s.NoRecord {
+			err = internal.WriteRecord(staged, s.record(inFs, template, answers))
+			if err != nil {
+				return err
+			}
+		}
+		err = internal.ResolveConflicts(staged, s.OutputFS, s.ConflictPolicy, s.prompter())
 		if err != nil {
-			s.cleanUp()
 			return err
 		}
-		inFs = path.Join(s.CloneCache, template)
+		return internal.CopyFS(staged, s.OutputFS)
 	}
 
-	err = internal.Create(inFs, s.Arguments, s.OutputFolder)
+	tx, err := internal.NewTransaction(s.OutputFolder)
 	if err != nil {
-		s.cleanUp()
+		return err
+	}
+	answers, err := internal.Create(inFs, s.input(s.Arguments), tx.FS())
+	if err == nil && !s.NoRecord {
+		err = internal.WriteRecord(tx.FS(), s.record(inFs, template, answers))
+	}
+	if err == nil {
+		err = internal.ResolveConflicts(tx.FS(), tx.Target(), s.ConflictPolicy, s.prompter())
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Plan renders the project in memory and describes the files Scaffold would
+// create, overwrite or skip under the ConflictPolicy, including the record of
+// the project, without writing to the OutputFolder or OutputFS.
+func (s *Scafall) Plan() (Plan, error) {
+	defer s.removeClone()
+	if err := s.readArguments(); err != nil {
+		return Plan{}, err
+	}
+	inFs, template, err := s.chooseTemplate()
+	if err != nil {
+		return Plan{}, err
+	}
+	if err := s.checkArguments(inFs); err != nil {
+		return Plan{}, err
 	}
 
-	return err
+	staged := memfs.New()
+	answers, err := internal.Create(inFs, s.input(s.Arguments), staged)
+	if err != nil {
+		return Plan{}, err
+	}
+	if !s.NoRecord {
+		err = internal.WriteRecord(staged, s.record(inFs, template, answers))
+		if err != nil {
+			return Plan{}, err
+		}
+	}
+	target := s.OutputFS
+	if target == nil {
+		target = osfs.New(s.OutputFolder)
+	}
+	return internal.NewPlan(staged, target, s.ConflictPolicy)
+}
+
+// Update merges the changes made to the template, since the revision recorded
+// in the project in the OutputFolder, into the project.  The new revision is
+// rendered with the recorded answers, overridden by any Arguments, asking for
+// any other prompt, and the recorded revision is rendered with the same
+// answers without asking.  The URL and SubPath are read from the record
+// unless they are set.  Lines changed both in the project and the template are
+// left between conflict markers.
+func (s *Scafall) Update() (UpdateResult, error) {
+	if err := s.readArguments(); err != nil {
+		return UpdateResult{}, err
+	}
+	record, err := internal.ReadRecord(osfs.New(s.OutputFolder))
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	if record.Commit == "" {
+		return UpdateResult{}, fmt.Errorf("%s does not record the git commit the project was generated from", internal.RecordFile)
+	}
+	if s.URL == "" {
+		s.URL = record.URL
+	}
+	if s.SubPath == "" {
+		s.SubPath = record.SubPath
+	}
+	arguments := map[string]string{}
+	for name, value := range record.Answers {
+		arguments[name] = internal.FormatValue(value)
+	}
+	for name, value := range s.Arguments {
+		arguments[name] = value
+	}
+
+	defer s.removeClone()
+	// a local repository is checked out, so that its commit is recorded
+	s.checkout = true
+	updatedFS, answers, recorded, err := s.render(record.Template, arguments)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	if s.Commit == "" {
+		return UpdateResult{}, fmt.Errorf("template %s is not a git repository, so the revision it is updated to cannot be recorded", s.URL)
+	}
+	// the base is rendered with the answers just given, passwords included,
+	// so that nothing is asked twice
+	base := *s
+	base.Ref = record.Commit
+	base.CloneCache, base.cloneRoot, base.release = "", "", nil
+	base.NonInteractive = true
+	defer base.removeClone()
+	baseArguments := map[string]string{}
+	for name, value := range answers {
+		baseArguments[name] = internal.FormatValue(value)
+	}
+	baseFS, _, _, err := base.render(record.Template, baseArguments)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	tx, err := internal.NewTransaction(s.OutputFolder)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	theirs := "template " + s.Commit
+	result, err := internal.MergeProject(baseFS, updatedFS, tx.Target(), tx.FS(), "local", theirs)
+	for _, path := range result.Removed {
+		tx.Remove(path)
+	}
+	if err == nil && !s.NoRecord {
+		record.Commit = s.Commit
+		record.Version = ScafallVersion()
+		record.Answers = recorded
+		err = internal.WriteRecord(tx.FS(), record)
+	}
+	if err != nil {
+		tx.Rollback()
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 // TemplateArguments returns a list of variable names that can be passed to the template
-func (s Scafall) TemplateArguments() (string, []string, error) {
-	err := s.clone()
+func (s *Scafall) TemplateArguments() (string, []string, error) {
+	defer s.removeClone()
+	inFs, err := s.templateFS()
 	if err != nil {
 		return "", nil, err
 	}
-	inFs := s.CloneCache
 	if isCollection, choices := internal.IsCollection(inFs); isCollection {
 		return "templates available in collection", choices, nil
 	}
 
-	promptFile := filepath.Join(inFs, internal.PromptFile)
-	p, err := os.Open(promptFile)
-	if err != nil {
-		s.cleanUp()
-		return "", nil, err
-	}
-	template, err := internal.NewTemplate(p, nil)
+	template, err := promptTemplate(inFs)
 	if err != nil {
-		s.cleanUp()
 		return "", nil, err
 	}
 	prompts := template.Arguments()
 	argsStrings := make([]string, len(prompts))
 	for i, p := range prompts {
-		if len(p.Choices) == 0 {
-			argsStrings[i] = fmt.Sprintf("%s (default: %s)", p.Name, p.Default)
-		} else {
-			cString := strings.Join(p.Choices, ", ")
-			argsStrings[i] = fmt.Sprintf("%s=%s (default: %s)", p.Name, cString, p.Choices[0])
-		}
+		argsStrings[i] = argumentSummary(p)
 	}
 	return "arguments offered by template", argsStrings, nil
 }
 
-func (s *Scafall) cleanUp() {
-	s.CloneCache = ""
-	os.RemoveAll(s.CloneCache)
-	os.RemoveAll(s.OutputFolder)
+// TemplateVariables returns the variables computed by the template, which
+// cannot be passed as arguments, as Name = expression.
+func (s *Scafall) TemplateVariables() ([]string, error) {
+	defer s.removeClone()
+	inFs, err := s.templateFS()
+	if err != nil {
+		return nil, err
+	}
+	if isCollection, _ := internal.IsCollection(inFs); isCollection {
+		return []string{}, nil
+	}
+
+	template, err := promptTemplate(inFs)
+	if err != nil {
+		return nil, err
+	}
+	variables := template.Variables()
+	varStrings := make([]string, len(variables))
+	for i, v := range variables {
+		varStrings[i] = variableSummary(v)
+	}
+	return varStrings, nil
+}
+
+// Read the prompts of the template in inFs.
+func promptTemplate(inFs fs.FS) (internal.Template, error) {
+	p, err := inFs.Open(internal.PromptFile)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	return internal.NewTemplate(p, nil)
+}
+
+// Add the arguments given by SCAFALL_ARG_ environment variables, then the
+// Answers, or the answers in the AnswersFile, to the Arguments that are not
+// already set.  The answers are only read once.
+func (s *Scafall) readArguments() error {
+	arguments := map[string]string{}
+	r, name := s.Answers, ""
+	switch s.AnswersFile {
+	case "":
+	case "-":
+		r = os.Stdin
+	default:
+		f, err := os.Open(s.AnswersFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r, name = f, s.AnswersFile
+	}
+	if r != nil {
+		answers, err := internal.ReadAnswers(r, name)
+		if err != nil {
+			return err
+		}
+		arguments = answers
+	}
+
+	for key, value := range internal.EnvArguments(os.Environ()) {
+		arguments[key] = value
+	}
+	for key, value := range s.Arguments {
+		arguments[key] = value
+	}
+	s.Arguments = arguments
+	s.Answers, s.AnswersFile = nil, ""
+	return nil
+}
+
+// Warn about the Arguments that answer no prompt of the template in inFs, or
+// fail when Strict is set.  A template without prompts takes any argument.
+func (s *Scafall) checkArguments(inFs fs.FS) error {
+	template, err := promptTemplate(inFs)
+	if err != nil {
+		return nil
+	}
+	problems := internal.CheckArguments(template, s.Arguments)
+	if len(problems) == 0 {
+		return nil
+	}
+	if s.Strict {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+	}
+	return nil
+}
+
+// Open the template, asking which project template to use when the template is
+// a collection.  The name of the chosen project template is returned.
+func (s *Scafall) chooseTemplate() (fs.FS, string, error) {
+	inFs, err := s.templateFS()
+	if err != nil {
+		return nil, "", err
+	}
+	if isCollection, options := internal.IsCollection(inFs); isCollection {
+		if s.NonInteractive {
+			return nil, "", fmt.Errorf("missing values in non-interactive mode:\n\ttemplate (choose a project template with a sub path): one of %s", strings.Join(options, ", "))
+		}
+		template, err := s.prompter().Select(internal.SelectQuestion{
+			Name:    "template",
+			Message: "choose a project template",
+			Options: options,
+			Validate: func(answer string) error {
+				for _, option := range options {
+					if answer == option {
+						return nil
+					}
+				}
+				return fmt.Errorf("expected one of %s", strings.Join(options, ", "))
+			},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		inFs, err = fs.Sub(inFs, template)
+		return inFs, template, err
+	}
+	return inFs, "", nil
+}
+
+// Render the named project template, or the template when name is empty, in
+// memory with the given arguments.  Every answer is returned along with the
+// answers to record.
+func (s *Scafall) render(name string, arguments map[string]string) (billy.Filesystem, map[string]interface{}, map[string]interface{}, error) {
+	inFs, err := s.templateFS()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if name != "" {
+		inFs, err = fs.Sub(inFs, name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	staged := memfs.New()
+	answers, err := internal.Create(inFs, s.input(arguments), staged)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return staged, answers, recordedAnswers(inFs, answers), nil
+}
+
+// Describe how the project was generated from the named project template in
+// inFs.  Local template paths are recorded as absolute paths.
+func (s *Scafall) record(inFs fs.FS, name string, answers map[string]interface{}) internal.Record {
+	url := s.URL
+	if _, err := os.Stat(url); err == nil && s.TemplateFS == nil {
+		if abs, err := filepath.Abs(url); err == nil {
+			url = abs
+		}
+	}
+	return internal.Record{
+		URL:      url,
+		SubPath:  s.SubPath,
+		Template: name,
+		Commit:   s.Commit,
+		Version:  ScafallVersion(),
+		Answers:  recordedAnswers(inFs, answers),
+	}
+}
+
+// The answers to record for the template in inFs.  A template without
+// prompts records every answer.
+func recordedAnswers(inFs fs.FS, answers map[string]interface{}) map[string]interface{} {
+	template, err := promptTemplate(inFs)
+	if err != nil {
+		return answers
+	}
+	return internal.RecordedAnswers(template, answers)
+}
+
+// Open the template, or collection of templates, as a filesystem.  A
+// TemplateFS is used in place of fetching the URL.
+func (s *Scafall) templateFS() (fs.FS, error) {
+	if s.TemplateFS != nil {
+		if s.SubPath == "" {
+			return s.TemplateFS, nil
+		}
+		if _, err := fs.Stat(s.TemplateFS, s.SubPath); err != nil {
+			return nil, fmt.Errorf("requested subPath of template does not exist: %s", s.SubPath)
+		}
+		return fs.Sub(s.TemplateFS, s.SubPath)
+	}
+
+	if err := s.clone(); err != nil {
+		return nil, err
+	}
+	return os.DirFS(s.CloneCache), nil
 }
 
 func (s *Scafall) clone() error {
@@ -149,11 +638,41 @@ func (s *Scafall) clone() error {
 	if err != nil {
 		return err
 	}
+	s.cloneRoot = tmpDir
 
-	fs, err := internal.URLToFs(s.URL, s.SubPath, tmpDir)
+	var cache *internal.Cache
+	if s.CacheDir != "" {
+		cache = &internal.Cache{Root: s.CacheDir, Offline: s.Offline, MaxAge: s.CacheMaxAge}
+	}
+	source := internal.Source{
+		URL:      s.URL,
+		Ref:      s.Ref,
+		SubPath:  s.SubPath,
+		Checksum: s.Checksum,
+		Cache:    cache,
+		Auth:     s.Auth,
+		Checkout: s.checkout,
+	}
+	dir, commit, release, err := internal.URLToFs(source, tmpDir)
 	if err != nil {
 		return err
 	}
-	s.CloneCache = fs
+	s.release = release
+	s.CloneCache = dir
+	s.Commit = commit
 	return nil
 }
+
+// Remove the working copy of the template, cached templates are kept but
+// released.
+func (s *Scafall) removeClone() {
+	if s.cloneRoot != "" {
+		os.RemoveAll(s.cloneRoot)
+	}
+	if s.release != nil {
+		s.release()
+	}
+	s.cloneRoot = ""
+	s.release = nil
+	s.CloneCache = ""
+}
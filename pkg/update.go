@@ -0,0 +1,9 @@
+package scafall
+
+import (
+	"github.com/buildpacks-community/scafall/pkg/internal"
+)
+
+// UpdateResult lists the files created, updated, removed or left with
+// conflicts by Update.
+type UpdateResult = internal.UpdateResult
@@ -0,0 +1,31 @@
+package scafall
+
+import "runtime/debug"
+
+const modulePath = "github.com/buildpacks-community/scafall"
+
+// Version of scafall, recorded in generated projects.  It may be set when
+// building with -ldflags "-X github.com/buildpacks-community/scafall/pkg.Version=v1.2.3",
+// and is otherwise read from the build info of the running program.
+var Version = ""
+
+// ScafallVersion is the Version of scafall, or the version of the scafall
+// module the running program was built with.
+func ScafallVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
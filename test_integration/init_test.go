@@ -11,5 +11,6 @@ func TestIntegration(t *testing.T) {
 	// Run in sequence as the tests change the pwd
 	suite := spec.New("scafall integration", spec.Sequential(), spec.Report(report.Terminal{}))
 	suite("scafall", testIntegration)
+	suite("update", testUpdate)
 	suite.Run(t)
 }
@@ -1,17 +1,26 @@
 package scafall_integration_test
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/sclevine/spec"
 	h "github.com/stretchr/testify/assert"
 
 	scafall "github.com/buildpacks-community/scafall/pkg"
 )
 
+//go:embed testdata/collection
+var embedded embed.FS
+
 func testIntegration(t *testing.T, when spec.G, it spec.S) {
 	type TestCase struct {
 		title         string
@@ -137,6 +146,84 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 		})
 	})
 
+	when("A subPath of an archive is requested", func() {
+		it("creates a project from the archive", func() {
+			archive := filepath.Join(t.TempDir(), "collection.tgz")
+			f, err := os.Create(archive)
+			h.Nil(t, err)
+			gz := gzip.NewWriter(f)
+			tw := tar.NewWriter(gz)
+			filepath.Walk("testdata/collection", func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				content, err := os.ReadFile(path)
+				h.Nil(t, err)
+				name := strings.TrimPrefix(path, "testdata/")
+				tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+				tw.Write(content)
+				return nil
+			})
+			tw.Close()
+			gz.Close()
+			f.Close()
+
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				archive,
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithSubPath("two"),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Contains(t, string(data), "this is not a test")
+		})
+	})
+
+	when("A template is embedded", func() {
+		it("creates a project from the embedded filesystem", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"",
+				scafall.WithTemplateFS(embedded),
+				scafall.WithSubPath("testdata/collection/two"),
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Contains(t, string(data), "this is not a test")
+		})
+	})
+
+	when("An output filesystem is provided", func() {
+		it("creates the project in memory", func() {
+			outputFS := memfs.New()
+			s, _ := scafall.NewScafall(
+				"testdata/template_folder",
+				scafall.WithArguments(map[string]string{"duck": "quack", "crow": "caw"}),
+				scafall.WithOutputFS(outputFS),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := util.ReadFile(outputFS, "quack/quack.go")
+			h.Nil(t, err)
+			h.Contains(t, string(data), "QUACK")
+			_, err = outputFS.Stat("quack/quack.jpg")
+			h.Nil(t, err)
+			_, err = os.Stat("quack")
+			h.True(t, os.IsNotExist(err))
+		})
+	})
+
 	when("An invalid template is passed", func() {
 		it("reports template errors and does not output a project", func() {
 			brokenTemplate := "testdata/broken"
@@ -151,6 +238,592 @@ func testIntegration(t *testing.T, when spec.G, it spec.S) {
 			_, err = os.Stat(templateFile)
 			h.NotNil(t, err)
 		})
+
+		it("keeps existing content of the output folder when rendering fails midway", func() {
+			outputDir := t.TempDir()
+			existing := filepath.Join(outputDir, "existing.txt")
+			err := os.WriteFile(existing, []byte("keep me"), 0600)
+			h.Nil(t, err)
+
+			s, _ := scafall.NewScafall(
+				"testdata/broken_render",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"Test": "test"}),
+			)
+			err = s.Scaffold()
+			h.NotNil(t, err)
+
+			_, err = os.Stat(filepath.Join(outputDir, "a.txt"))
+			h.True(t, os.IsNotExist(err))
+			data, err := os.ReadFile(existing)
+			h.Nil(t, err)
+			h.Equal(t, "keep me", string(data))
+			entries, err := os.ReadDir(filepath.Dir(outputDir))
+			h.Nil(t, err)
+			h.Len(t, entries, 1)
+		})
+	})
+
+	when("The output folder contains a generated file", func() {
+		var outputDir string
+
+		it.Before(func() {
+			outputDir = t.TempDir()
+			err := os.WriteFile(filepath.Join(outputDir, "template.go"), []byte("mine"), 0600)
+			h.Nil(t, err)
+		})
+
+		it("fails without changing the output folder by default", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/collection/two",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+			)
+			err := s.Scaffold()
+			h.ErrorContains(t, err, "template.go")
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Equal(t, "mine", string(data))
+		})
+
+		it("backs up the existing file", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/collection/two",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+				scafall.WithConflictPolicy(scafall.ConflictBackup),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Contains(t, string(data), "this is not a test")
+			data, err = os.ReadFile(filepath.Join(outputDir, "template.go.bak"))
+			h.Nil(t, err)
+			h.Equal(t, "mine", string(data))
+		})
+	})
+
+	when("A plan is requested", func() {
+		it("describes the project without writing it", func() {
+			outputDir := t.TempDir()
+			err := os.WriteFile(filepath.Join(outputDir, "template.go"), []byte("mine"), 0600)
+			h.Nil(t, err)
+			s, _ := scafall.NewScafall(
+				"testdata/collection/two",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+				scafall.WithConflictPolicy(scafall.ConflictOverwrite),
+			)
+			plan, err := s.Plan()
+			h.Nil(t, err)
+
+			h.Len(t, plan.Files, 2)
+			h.Equal(t, filepath.Join(".scafall", "answers.toml"), plan.Files[0].Path)
+			h.Equal(t, scafall.ActionCreate, plan.Files[0].Action)
+			h.Equal(t, "template.go", plan.Files[1].Path)
+			h.Equal(t, scafall.ActionOverwrite, plan.Files[1].Action)
+			h.Contains(t, plan.Files[1].Diff, "+this is not a test")
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Equal(t, "mine", string(data))
+			_, err = os.Stat(filepath.Join(outputDir, ".scafall"))
+			h.True(t, os.IsNotExist(err))
+		})
+
+		it("reports an existing record that differs as a conflict", func() {
+			outputDir := t.TempDir()
+			err := os.MkdirAll(filepath.Join(outputDir, ".scafall"), 0755)
+			h.Nil(t, err)
+			err = os.WriteFile(filepath.Join(outputDir, ".scafall", "answers.toml"), []byte("mine"), 0600)
+			h.Nil(t, err)
+			s, _ := scafall.NewScafall(
+				"testdata/collection/two",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"TestPrompt": "test"}),
+			)
+			plan, err := s.Plan()
+			h.Nil(t, err)
+
+			h.Len(t, plan.Files, 2)
+			h.Equal(t, filepath.Join(".scafall", "answers.toml"), plan.Files[0].Path)
+			h.Equal(t, scafall.ActionConflict, plan.Files[0].Action)
+			h.Equal(t, scafall.ActionCreate, plan.Files[1].Action)
+		})
+	})
+
+	when("Prompts declare a type", func() {
+		it("renders typed arguments", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/typed_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"UseDocker": "true", "Port": "8080", "Features": "logging,tracing"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "config.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "docker\nport 8081\n- logging\n- tracing\n\n", string(data))
+		})
+
+		it("rejects arguments of the wrong type", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/typed_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"UseDocker": "true", "Port": "80", "Features": ""}),
+			)
+			err := s.Scaffold()
+			h.ErrorContains(t, err, "Port must be at least 1024")
+		})
+	})
+
+	when("Answers are read from a file", func() {
+		it("parses typed values, overridden by arguments", func() {
+			outputDir := t.TempDir()
+			answers := "UseDocker: true\nPort: 8080\nFeatures: [logging, tracing]\n"
+			s, _ := scafall.NewScafall(
+				"testdata/typed_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithAnswers(strings.NewReader(answers)),
+				scafall.WithArguments(map[string]string{"Port": "9090"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "config.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "docker\nport 9091\n- logging\n- tracing\n\n", string(data))
+		})
+
+		it("reads the format given by the file extension", func() {
+			outputDir := t.TempDir()
+			answersFile := filepath.Join(t.TempDir(), "answers.json")
+			err := os.WriteFile(answersFile, []byte(`{"UseDocker": false, "Port": 8080, "Features": []}`), 0600)
+			h.Nil(t, err)
+			s, _ := scafall.NewScafall(
+				"testdata/typed_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithAnswersFile(answersFile),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "config.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "no docker\nport 8081\n\n", string(data))
+		})
+	})
+
+	when("Running non-interactively", func() {
+		it("takes the defaults of prompts", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/typed_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "config.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "no docker\nport 8081\n\n", string(data))
+		})
+
+		it("lists the prompts without a default", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/requireprompts",
+				scafall.WithOutputFolder(t.TempDir()),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.ErrorContains(t, err, "missing values in non-interactive mode:\n\tTest (Enter test value)")
+		})
+
+		it("requires a template of a collection to be chosen", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/collection",
+				scafall.WithOutputFolder(t.TempDir()),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.EqualError(t, err, "missing values in non-interactive mode:\n\ttemplate (choose a project template with a sub path): one of one, two")
+		})
+	})
+
+	when("A project is recorded", func() {
+		it("records the template and answers but not passwords", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/recorded",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"ProjectName": "MyProject", "Token": "secret", "Features": "logging,tracing"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, ".scafall", "answers.toml"))
+			h.Nil(t, err)
+			abs, _ := filepath.Abs("testdata/recorded")
+			expected := fmt.Sprintf("url = %q\nscafall_version = %q\n\n[answers]\n  Features = [\"logging\", \"tracing\"]\n  ProjectName = \"MyProject\"\n", abs, scafall.ScafallVersion())
+			h.Equal(t, expected, string(data))
+		})
+
+		it("handles an existing record that differs as a conflict", func() {
+			outputDir := t.TempDir()
+			err := os.MkdirAll(filepath.Join(outputDir, ".scafall"), 0755)
+			h.Nil(t, err)
+			err = os.WriteFile(filepath.Join(outputDir, ".scafall", "answers.toml"), []byte("mine"), 0600)
+			h.Nil(t, err)
+			arguments := map[string]string{"ProjectName": "MyProject", "Token": "", "Features": ""}
+
+			s, _ := scafall.NewScafall("testdata/recorded", scafall.WithOutputFolder(outputDir), scafall.WithArguments(arguments))
+			err = s.Scaffold()
+			h.ErrorContains(t, err, "answers.toml")
+			data, err := os.ReadFile(filepath.Join(outputDir, ".scafall", "answers.toml"))
+			h.Nil(t, err)
+			h.Equal(t, "mine", string(data))
+
+			s, _ = scafall.NewScafall(
+				"testdata/recorded",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(arguments),
+				scafall.WithConflictPolicy(scafall.ConflictSkip),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+			data, err = os.ReadFile(filepath.Join(outputDir, ".scafall", "answers.toml"))
+			h.Nil(t, err)
+			h.Equal(t, "mine", string(data))
+		})
+
+		it("does not record a project when asked not to", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/recorded",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"ProjectName": "MyProject", "Token": "", "Features": ""}),
+				scafall.WithoutRecord(),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			_, err = os.Stat(filepath.Join(outputDir, ".scafall"))
+			h.True(t, os.IsNotExist(err))
+		})
+
+		it("generates the project again from its record", func() {
+			projectDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/recorded",
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithArguments(map[string]string{"ProjectName": "MyProject", "Token": "secret", "Features": "metrics"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			replayDir := t.TempDir()
+			replay, err := scafall.NewScafallFromRecord(
+				projectDir,
+				scafall.WithOutputFolder(replayDir),
+				scafall.WithArguments(map[string]string{"Token": "other"}),
+			)
+			h.Nil(t, err)
+			err = replay.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(replayDir, "project.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "package my_project\ntoken other\n- metrics\n", string(data))
+		})
+
+		it("backs up edited files when replaying in place", func() {
+			projectDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/recorded",
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithArguments(map[string]string{"ProjectName": "MyProject", "Token": "", "Features": "metrics"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+			err = os.WriteFile(filepath.Join(projectDir, "project.txt"), []byte("edited"), 0600)
+			h.Nil(t, err)
+
+			replay, err := scafall.NewScafallFromRecord(projectDir)
+			h.Nil(t, err)
+			err = replay.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(projectDir, "project.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "package my_project\ntoken \n- metrics\n", string(data))
+			data, err = os.ReadFile(filepath.Join(projectDir, "project.txt.bak"))
+			h.Nil(t, err)
+			h.Equal(t, "edited", string(data))
+		})
+
+		it("fails to replay a project without a record", func() {
+			_, err := scafall.NewScafallFromRecord(t.TempDir())
+			h.ErrorContains(t, err, "project was not generated by scafall")
+		})
+	})
+
+	when("A user config holds answers", func() {
+		it("applies answers in order of precedence", func() {
+			outputDir := t.TempDir()
+			config := scafall.Config{
+				Answers: map[string]interface{}{"AuthorName": "Jane Doe", "Org": "personal", "License": "MIT", "Name": "global"},
+				Templates: []scafall.TemplateConfig{
+					{URL: "testdata/config_answers", Answers: map[string]interface{}{"Org": "org", "Name": "template"}},
+				},
+			}
+			s, _ := scafall.NewScafall(
+				"testdata/config_answers",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithConfig(config),
+				scafall.WithAnswers(strings.NewReader(`License = "BSD"`)),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "project.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "Jane Doe org BSD template\n", string(data))
+		})
+
+		it("prefers arguments to fixed answers", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/config_answers",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithConfig(scafall.Config{
+					Answers: map[string]interface{}{"AuthorName": "Jane Doe", "Org": "org"},
+					Fixed:   []string{"AuthorName", "Org"},
+				}),
+				scafall.WithArguments(map[string]string{"Org": "argument"}),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "project.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "Jane Doe argument Apache-2.0 app\n", string(data))
+		})
+	})
+
+	when("Arguments are given by the environment", func() {
+		it("prefers explicit arguments to the environment", func() {
+			t.Setenv("SCAFALL_ARG_Org", "env-org")
+			t.Setenv("SCAFALL_ARG_Name", "env-name")
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/config_answers",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithAnswers(strings.NewReader(`{"Org": "file-org", "License": "file-license"}`)),
+				scafall.WithArguments(map[string]string{"Name": "arg-name"}),
+				scafall.WithNonInteractive(),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "project.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "nobody env-org file-license arg-name\n", string(data))
+		})
+	})
+
+	when("A prompter is provided", func() {
+		it("asks which template of a collection to use and its prompts", func() {
+			outputDir := t.TempDir()
+			prompter := scafall.ScriptedPrompter{Answers: map[string]string{"template": "two", "TestPrompt": "test"}}
+			s, _ := scafall.NewScafall(
+				"testdata/collection",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithPrompter(prompter),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "template.go"))
+			h.Nil(t, err)
+			h.Contains(t, string(data), "this is not a test")
+		})
+
+		it("reports answers that are not options", func() {
+			prompter := scafall.ScriptedPrompter{Answers: map[string]string{"template": "three"}}
+			s, _ := scafall.NewScafall(
+				"testdata/collection",
+				scafall.WithOutputFolder(t.TempDir()),
+				scafall.WithPrompter(prompter),
+			)
+			err := s.Scaffold()
+			h.ErrorContains(t, err, `answer "three" to template is not valid: expected one of one, two`)
+		})
+
+		it("stays non-interactive when a prompter is given", func() {
+			prompter := scafall.ScriptedPrompter{Answers: map[string]string{"template": "two"}}
+			s, _ := scafall.NewScafall("testdata/collection", scafall.WithPrompter(prompter))
+			h.False(t, s.NonInteractive)
+			s, _ = scafall.NewScafall("testdata/collection", scafall.WithNonInteractive(), scafall.WithPrompter(prompter))
+			h.True(t, s.NonInteractive)
+		})
+	})
+
+	when("Prompts depend on earlier answers", func() {
+		it("skips prompts that do not apply", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/conditional_prompts",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"BuildTool": "maven"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "build.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "maven none\n", string(data))
+		})
+	})
+
+	when("Prompt defaults are templates", func() {
+		it("lists defaults that depend on other answers unevaluated", func() {
+			s, _ := scafall.NewScafall("testdata/templated_defaults")
+			_, args, err := s.TemplateArguments()
+			h.Nil(t, err)
+			h.Equal(t, []string{
+				"ProjectName (default: DUCK)",
+				"ArtifactId (default: {{ .ProjectName | kebabcase }})",
+			}, args)
+		})
+	})
+
+	when("A template computes variables", func() {
+		it("renders files with the computed values", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/computed_variables",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"ProjectName": "MyDuck"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "my_duck", "__init__.py"))
+			h.Nil(t, err)
+			h.Equal(t, "import my_duck\n", string(data))
+		})
+
+		it("lists computed variables separately from arguments", func() {
+			s, _ := scafall.NewScafall("testdata/computed_variables")
+			_, args, err := s.TemplateArguments()
+			h.Nil(t, err)
+			h.Equal(t, []string{"ProjectName (default: )"}, args)
+			vars, err := s.TemplateVariables()
+			h.Nil(t, err)
+			h.Equal(t, []string{"Module = {{ .ProjectName | snakecase | lower }}"}, vars)
+		})
+	})
+
+	when("Choices have labels", func() {
+		it("accepts the label of a choice as an argument", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/labelled_choices",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"SpringBootVersion": "Spring Boot 3 (recommended)", "Python": "python3.9"}),
+			)
+			err := s.Scaffold()
+			h.Nil(t, err)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "versions.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "spring-boot 3.2.1\npython3.9\n", string(data))
+		})
+
+		it("lists both the labels and values of choices", func() {
+			s, _ := scafall.NewScafall("testdata/labelled_choices")
+			description, err := s.Describe()
+			h.Nil(t, err)
+			h.Equal(t, `arguments offered by template
+	SpringBootVersion=3.2.1 [Spring Boot 3 (recommended)], 2.7.18 [Spring Boot 2] (select, default: 3.2.1)
+		3.2.1: Requires Java 17
+	Python=python3.10, python3.9 (select, default: python3.10)
+`, description.String())
+		})
+
+		it("gives the type of each prompt", func() {
+			s, _ := scafall.NewScafall("testdata/typed_prompts")
+			description, err := s.Describe()
+			h.Nil(t, err)
+			h.Equal(t, `arguments offered by template
+	UseDocker (bool, default: )
+	Port (int, default: 8080)
+	Features=logging, metrics, tracing (multiselect, default: )
+`, description.String())
+		})
+	})
+
+	when("Arguments do not match the prompts", func() {
+		it("rejects a value that is not a choice", func() {
+			s, _ := scafall.NewScafall(
+				"testdata/labelled_choices",
+				scafall.WithOutputFolder(t.TempDir()),
+				scafall.WithArguments(map[string]string{"SpringBootVersion": "2.7.18", "Python": "python3.8"}),
+			)
+			err := s.Scaffold()
+			h.ErrorContains(t, err, `Python must be one of python3.10, python3.9, not "python3.8"`)
+		})
+
+		it("fails on unknown arguments in strict mode", func() {
+			outputDir := t.TempDir()
+			s, _ := scafall.NewScafall(
+				"testdata/labelled_choices",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"SpringBootVersion": "2.7.18", "Python": "python3.9", "Pyhton": "python3.9"}),
+				scafall.WithStrict(),
+			)
+			err := s.Scaffold()
+			h.EqualError(t, err, "unknown argument Pyhton, did you mean Python?")
+			_, err = os.Stat(filepath.Join(outputDir, "versions.txt"))
+			h.True(t, os.IsNotExist(err))
+		})
+	})
+
+	when("A template is described", func() {
+		it("includes the description, help and examples of prompts", func() {
+			s, _ := scafall.NewScafall("testdata/described_prompts")
+			description, err := s.Describe()
+			h.Nil(t, err)
+			h.Len(t, description.Prompts, 1)
+			h.Equal(t, "Path of the Go module", description.Prompts[0].Description)
+			h.Equal(t, []string{"github.com/org/name", "example.com/name"}, description.Prompts[0].Examples)
+			h.Equal(t, []scafall.Variable{{Name: "Base", Value: "{{ base .ModulePath }}"}}, description.Variables)
+
+			h.Equal(t, `arguments offered by template
+	ModulePath (default: )
+		Path of the Go module
+		The module path is used in go.mod
+		and import statements
+		examples: github.com/org/name, example.com/name
+variables computed by template
+	Base = {{ base .ModulePath }}
+`, description.String())
+		})
+
+		it("lists the templates of a collection", func() {
+			s, _ := scafall.NewScafall("testdata/collection")
+			description, err := s.Describe()
+			h.Nil(t, err)
+			h.Equal(t, []string{"one", "two"}, description.Templates)
+		})
 	})
 
 	when("various sprig functions are used", func() {
@@ -0,0 +1,219 @@
+package scafall_integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sclevine/spec"
+	h "github.com/stretchr/testify/assert"
+
+	scafall "github.com/buildpacks-community/scafall/pkg"
+)
+
+// commit files to repo and tag the commit
+func commitVersion(t *testing.T, repo *git.Repository, dir string, tag string, files map[string]string) {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	h.Nil(t, err)
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		h.Nil(t, err)
+		_, err = worktree.Add(name)
+		h.Nil(t, err)
+	}
+	hash, err := worktree.Commit(tag, &git.CommitOptions{
+		All:    true,
+		Author: &object.Signature{Name: "scafall", Email: "scafall@example.com", When: time.Now()},
+	})
+	h.Nil(t, err)
+	_, err = repo.CreateTag(tag, hash, nil)
+	h.Nil(t, err)
+}
+
+// counts the text questions asked
+type countingPrompter struct {
+	scafall.ScriptedPrompter
+	asked *int
+}
+
+func (p countingPrompter) Text(question scafall.TextQuestion) (string, error) {
+	*p.asked++
+	return p.ScriptedPrompter.Text(question)
+}
+
+func testUpdate(t *testing.T, when spec.G, it spec.S) {
+	when("A generated project is updated", func() {
+		var (
+			workDir   string
+			bareDir   string
+			outputDir string
+			cacheDir  string
+		)
+
+		it.Before(func() {
+			workDir = t.TempDir()
+			repo, err := git.PlainInit(workDir, false)
+			h.Nil(t, err)
+			prompts := "[[prompt]]\nname = \"Name\"\nprompt = \"name\"\n"
+			commitVersion(t, repo, workDir, "v1", map[string]string{
+				"prompts.toml":  prompts,
+				"{{.Name}}.txt": "hello {{.Name}}\n1\n2\n3\n4\n5\n",
+				"old.txt":       "removed in v2\n",
+			})
+			err = os.Remove(filepath.Join(workDir, "old.txt"))
+			h.Nil(t, err)
+			commitVersion(t, repo, workDir, "v2", map[string]string{
+				"{{.Name}}.txt": "hello {{.Name}}\n1\n2\n3\n4\nfive\n",
+				"new.txt":       "added in v2\n",
+			})
+			bareDir = t.TempDir()
+			_, err = git.PlainClone(bareDir, true, &git.CloneOptions{URL: workDir})
+			h.Nil(t, err)
+
+			outputDir = filepath.Join(t.TempDir(), "project")
+			cacheDir = t.TempDir()
+			s, _ := scafall.NewScafall(
+				bareDir+"@v1",
+				scafall.WithOutputFolder(outputDir),
+				scafall.WithArguments(map[string]string{"Name": "quack"}),
+				scafall.WithCacheDir(cacheDir),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+		})
+
+		it("merges template changes with local changes", func() {
+			err := os.WriteFile(filepath.Join(outputDir, "quack.txt"), []byte("hello quack\none\n2\n3\n4\n5\n"), 0600)
+			h.Nil(t, err)
+
+			s, _ := scafall.NewScafall("", scafall.WithOutputFolder(outputDir), scafall.WithRef("v2"), scafall.WithCacheDir(cacheDir))
+			result, err := s.Update()
+			h.Nil(t, err)
+			h.Equal(t, []string{"quack.txt"}, result.Updated)
+			h.Equal(t, []string{"new.txt"}, result.Created)
+			h.Equal(t, []string{"old.txt"}, result.Removed)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "quack.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "hello quack\none\n2\n3\n4\nfive\n", string(data))
+			_, err = os.Stat(filepath.Join(outputDir, "old.txt"))
+			h.True(t, os.IsNotExist(err))
+		})
+
+		it("marks conflicting changes", func() {
+			err := os.WriteFile(filepath.Join(outputDir, "quack.txt"), []byte("hello quack\n1\n2\n3\n4\nsix\n"), 0600)
+			h.Nil(t, err)
+
+			s, _ := scafall.NewScafall("", scafall.WithOutputFolder(outputDir), scafall.WithRef("v2"), scafall.WithCacheDir(cacheDir))
+			result, err := s.Update()
+			h.Nil(t, err)
+			h.Equal(t, []string{"quack.txt"}, result.Conflicts)
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "quack.txt"))
+			h.Nil(t, err)
+			h.Contains(t, string(data), "<<<<<<< local\nsix\n=======\nfive\n>>>>>>> template ")
+		})
+
+		it("updates to the HEAD of the repository without a ref", func() {
+			s, _ := scafall.NewScafall("", scafall.WithOutputFolder(outputDir), scafall.WithCacheDir(cacheDir))
+			result, err := s.Update()
+			h.Nil(t, err)
+			h.Equal(t, []string{"new.txt"}, result.Created)
+			h.NotEqual(t, "", s.Commit)
+
+			result, err = s.Update()
+			h.Nil(t, err)
+			h.Equal(t, scafall.UpdateResult{}, result)
+		})
+
+		it("uses the working tree of a local repository without a ref", func() {
+			err := os.WriteFile(filepath.Join(workDir, "uncommitted.txt"), []byte("not committed\n"), 0600)
+			h.Nil(t, err)
+			projectDir := filepath.Join(t.TempDir(), "project")
+			s, _ := scafall.NewScafall(
+				workDir,
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithArguments(map[string]string{"Name": "quack"}),
+				scafall.WithCacheDir(cacheDir),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+			h.Equal(t, "", s.Commit)
+			data, err := os.ReadFile(filepath.Join(projectDir, "uncommitted.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "not committed\n", string(data))
+		})
+
+		it("uses a local repository without commits", func() {
+			templateDir := t.TempDir()
+			_, err := git.PlainInit(templateDir, false)
+			h.Nil(t, err)
+			err = os.WriteFile(filepath.Join(templateDir, "{{.Name}}.txt"), []byte("hello {{.Name}}\n"), 0600)
+			h.Nil(t, err)
+
+			projectDir := filepath.Join(t.TempDir(), "project")
+			s, _ := scafall.NewScafall(
+				templateDir,
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithArguments(map[string]string{"Name": "quack"}),
+				scafall.WithCacheDir(cacheDir),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+			data, err := os.ReadFile(filepath.Join(projectDir, "quack.txt"))
+			h.Nil(t, err)
+			h.Equal(t, "hello quack\n", string(data))
+		})
+
+		it("refuses a template that is not a git repository", func() {
+			templateDir := t.TempDir()
+			err := os.WriteFile(filepath.Join(templateDir, "{{.Name}}.txt"), []byte("hello {{.Name}}\n"), 0600)
+			h.Nil(t, err)
+
+			s, _ := scafall.NewScafall(templateDir, scafall.WithOutputFolder(outputDir), scafall.WithCacheDir(cacheDir))
+			_, err = s.Update()
+			h.ErrorContains(t, err, "is not a git repository")
+		})
+
+		it("asks for a password once", func() {
+			templateDir := t.TempDir()
+			repo, err := git.PlainInit(templateDir, false)
+			h.Nil(t, err)
+			prompts := "[[prompt]]\nname = \"Token\"\nprompt = \"token\"\ntype = \"password\"\n"
+			commitVersion(t, repo, templateDir, "v1", map[string]string{"prompts.toml": prompts, "token.txt": "{{.Token}}\n"})
+			commitVersion(t, repo, templateDir, "v2", map[string]string{"new.txt": "added in v2\n"})
+			projectDir := filepath.Join(t.TempDir(), "project")
+			s, _ := scafall.NewScafall(
+				templateDir+"@v1",
+				scafall.WithOutputFolder(projectDir),
+				scafall.WithArguments(map[string]string{"Token": "secret"}),
+				scafall.WithCacheDir(cacheDir),
+			)
+			err = s.Scaffold()
+			h.Nil(t, err)
+
+			asked := 0
+			prompter := countingPrompter{scafall.ScriptedPrompter{Answers: map[string]string{"Token": "secret"}}, &asked}
+			s, _ = scafall.NewScafall("", scafall.WithOutputFolder(projectDir), scafall.WithRef("v2"), scafall.WithCacheDir(cacheDir), scafall.WithPrompter(prompter))
+			result, err := s.Update()
+			h.Nil(t, err)
+			h.Equal(t, 1, asked)
+			h.Equal(t, []string{"new.txt"}, result.Created)
+			h.Empty(t, result.Updated)
+		})
+
+		it("records the new template revision", func() {
+			s, _ := scafall.NewScafall("", scafall.WithOutputFolder(outputDir), scafall.WithRef("v2"), scafall.WithCacheDir(cacheDir))
+			_, err := s.Update()
+			h.Nil(t, err)
+
+			result, err := s.Update()
+			h.Nil(t, err)
+			h.Equal(t, scafall.UpdateResult{}, result)
+		})
+	})
+}